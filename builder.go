@@ -0,0 +1,102 @@
+package hermes
+
+import (
+	"fmt"
+
+	cryptography "github.com/prulloac/hermes-jwt/cryptography"
+)
+
+// Builder assembles a JOSE header and claims set and signs them into a compact JWS.
+// A Builder is not safe for concurrent use.
+type Builder struct {
+	header JoseHeader
+	claims JWTClaimsSet
+	err    error
+}
+
+func NewBuilder() *Builder {
+	return &Builder{header: JoseHeader{}}
+}
+
+// Header merges the parameters of h into the header being built.
+func (b *Builder) Header(h JoseHeader) *Builder {
+	for k, v := range h {
+		b.header[k] = v
+	}
+	return b
+}
+
+func (b *Builder) HeaderParameter(name string, value interface{}) *Builder {
+	b.header[name] = value
+	return b
+}
+
+// Claims merges the claims of c into the claims set being built, replacing claims with the same name.
+func (b *Builder) Claims(c JWTClaimsSet) *Builder {
+	for _, claim := range c.Claims {
+		b.claims.AddClaim(claim)
+	}
+	return b
+}
+
+// Encode merges the JSON object encoding of v, typically a struct embedding RegisteredClaims, into the
+// claims set being built. An encoding error is returned by Sign.
+func (b *Builder) Encode(v interface{}) *Builder {
+	claims, err := EncodeClaims(v)
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return b
+	}
+	return b.Claims(claims)
+}
+
+func (b *Builder) Claim(name string, value interface{}) *Builder {
+	b.claims.SetClaimValue(name, value)
+	return b
+}
+
+// Sign sets the "alg" header to alg and signs the claims with key. It returns the compact serialization
+// and the signed JWT in SignatureVerified state.
+func (b *Builder) Sign(alg string, key interface{}) (string, JWT, error) {
+	if b.err != nil {
+		return "", JWT{}, b.err
+	}
+	if !IsJWS(alg) || alg == cryptography.AlgorithmNone {
+		return "", JWT{}, fmt.Errorf("unsupported algorithm")
+	}
+	jwt, err := b.build(alg).signed(key)
+	if err != nil {
+		return "", JWT{}, err
+	}
+	return jwt.compact, jwt, nil
+}
+
+// Unsecured builds an unsecured JWT per RFC 7519 §6, with "alg" set to "none" and an empty signature
+// segment. The claims carry no integrity protection, so this is only meant for tokens whose integrity is
+// ensured by other means.
+func (b *Builder) Unsecured() (string, JWT, error) {
+	if b.err != nil {
+		return "", JWT{}, b.err
+	}
+	jwt := b.build(cryptography.AlgorithmNone)
+	payload, err := jwt.payload.toJSON()
+	if err != nil {
+		return "", JWT{}, err
+	}
+	jwt.compact = jwt.header.ToBase64URL() + "." + encodeSegment(payload) + "."
+	return jwt.compact, jwt, nil
+}
+
+// build copies the header and claims built so far into a new JWT using alg, so that later
+// changes to the Builder do not affect it.
+func (b *Builder) build(alg string) JWT {
+	header := make(JoseHeader, len(b.header)+1)
+	for k, v := range b.header {
+		header[k] = v
+	}
+	header["alg"] = alg
+	claims := JWTClaimsSet{Claims: append([]Claim(nil), b.claims.Claims...)}
+	return NewJWT(header, claims)
+}
@@ -0,0 +1,105 @@
+package hermes
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilderSign(t *testing.T) {
+	key := []byte("a very secret key of at least 32 bytes")
+	compact, jwt, err := NewBuilder().
+		Header(JoseHeader{"typ": "JWT"}).
+		Claims(NewJWTClaimsSet(map[string]interface{}{"sub": "1234567890", "admin": true})).
+		Claim("name", "John Doe").
+		Sign("HS256", key)
+	assert.NoError(t, err)
+	assert.Equal(t, SignatureVerified, jwt.State())
+	assert.Equal(t, compact, jwt.String())
+	assert.Equal(t, "HS256", jwt.Algorithm())
+	assert.Equal(t, "JWT", jwt.Header().Parameter("typ"))
+
+	parsed, err := ParseJWS(compact)
+	assert.NoError(t, err)
+	assert.NoError(t, parsed.Verify(key))
+	assert.Equal(t, SignatureVerified, parsed.State())
+	name, err := parsed.Claims().GetClaimValue("name")
+	assert.NoError(t, err)
+	assert.Equal(t, "John Doe", name)
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	compact, _, err = NewBuilder().Claim("sub", "1234567890").Sign("ES256", ecKey)
+	assert.NoError(t, err)
+	parsed, err = ParseJWS(compact)
+	assert.NoError(t, err)
+	assert.NoError(t, parsed.Verify(&ecKey.PublicKey))
+	assert.Equal(t, SignatureVerified, parsed.State())
+}
+
+func TestBuilderSignInvalid(t *testing.T) {
+	b := NewBuilder().Claim("sub", "1234567890")
+	_, _, err := b.Sign("none", nil)
+	assert.Error(t, err)
+	_, _, err = b.Sign(AlgorithmA128KW, []byte("key"))
+	assert.Error(t, err)
+	_, _, err = b.Sign("ES256", []byte("key"))
+	assert.Error(t, err)
+	_, _, err = NewBuilder().Claim("bad", make(chan int)).Sign("HS256", []byte("key"))
+	assert.Error(t, err)
+}
+
+func TestBuilderEncode(t *testing.T) {
+	exp := NumericDate(1300819380)
+	_, jwt, err := NewBuilder().
+		Encode(struct {
+			RegisteredClaims
+			Scope string `json:"scope"`
+		}{RegisteredClaims{Issuer: "joe", ExpiresAt: &exp}, "read"}).
+		Claim("scope", "write").
+		Sign("HS256", []byte("key"))
+	assert.NoError(t, err)
+	r, err := jwt.Claims().RegisteredClaims()
+	assert.NoError(t, err)
+	assert.Equal(t, StringOrURI("joe"), r.Issuer)
+	assert.Equal(t, exp, *r.ExpiresAt)
+	scope, _ := jwt.Claims().GetClaimValue("scope")
+	assert.Equal(t, "write", scope)
+
+	_, _, err = NewBuilder().Encode("not an object").Sign("HS256", []byte("key"))
+	assert.Error(t, err)
+}
+
+func TestBuilderUnsecured(t *testing.T) {
+	compact, jwt, err := NewBuilder().Claim("iss", "joe").Claim("http://example.com/is_root", true).Unsecured()
+	assert.NoError(t, err)
+	assert.Equal(t, Unsecured, jwt.State())
+	assert.False(t, jwt.IsSecured())
+	assert.Equal(t, compact, jwt.String())
+	assert.True(t, strings.HasSuffix(compact, "."))
+	assert.Len(t, strings.Split(compact, "."), 3)
+
+	// Parsing requires the explicit opt-in
+	_, err = ParseJWS(compact)
+	assert.Error(t, err)
+	parsed, err := ParseJWS(compact, AllowUnsecured())
+	assert.NoError(t, err)
+	assert.Equal(t, Unsecured, parsed.State())
+	assert.Empty(t, parsed.signature)
+	iss, _ := parsed.Claims().GetClaimValue("iss")
+	assert.Equal(t, "joe", iss)
+	assert.Error(t, parsed.Verify([]byte("key")))
+	assert.Equal(t, Unsecured, parsed.State())
+
+	// RFC 7519 §6.1 example
+	parsed, err = ParseJWS("eyJhbGciOiJub25lIn0.eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ.", AllowUnsecured())
+	assert.NoError(t, err)
+	assert.Equal(t, Unsecured, parsed.State())
+
+	// An unsecured JWT must not carry a signature
+	_, err = ParseJWS(compact+"c2lnbmF0dXJl", AllowUnsecured())
+	assert.Error(t, err)
+}
@@ -0,0 +1,268 @@
+// Reference: https://datatracker.ietf.org/doc/html/rfc7519#section-2
+package hermes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StringOrURI is an arbitrary string, except that any value containing a ":" must be a URI.
+type StringOrURI string
+
+// Validate reports an error when a value containing a ":" is not an absolute URI.
+func (s StringOrURI) Validate() error {
+	if !strings.Contains(string(s), ":") {
+		return nil
+	}
+	u, err := url.Parse(string(s))
+	if err != nil || u.Scheme == "" {
+		return fmt.Errorf("invalid StringOrURI %q: values containing ':' must be URIs", string(s))
+	}
+	return nil
+}
+
+func (s *StringOrURI) UnmarshalJSON(b []byte) error {
+	var value string
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if err := StringOrURI(value).Validate(); err != nil {
+		return err
+	}
+	*s = StringOrURI(value)
+	return nil
+}
+
+// NumericDate is the number of seconds since 1970-01-01T00:00:00Z UTC, possibly fractional.
+type NumericDate float64
+
+func NewNumericDate(t time.Time) NumericDate {
+	return NumericDate(float64(t.Unix()) + float64(t.Nanosecond())/1e9)
+}
+
+// NewNumericDateFromUnix returns the NumericDate of a whole number of seconds since the epoch.
+func NewNumericDateFromUnix(seconds int64) NumericDate {
+	return NumericDate(seconds)
+}
+
+// Unix returns the whole seconds since the epoch, truncating any fractional part.
+func (d NumericDate) Unix() int64 {
+	return int64(d)
+}
+
+func (d NumericDate) Time() time.Time {
+	whole, frac := math.Modf(float64(d))
+	return time.Unix(int64(whole), int64(math.Round(frac*1e9)))
+}
+
+// MarshalJSON writes whole seconds without a fractional part or exponent.
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	f := float64(d)
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("invalid NumericDate")
+	}
+	return []byte(strconv.FormatFloat(f, 'f', -1, 64)), nil
+}
+
+func (d *NumericDate) UnmarshalJSON(b []byte) error {
+	var value float64
+	if err := json.Unmarshal(b, &value); err != nil {
+		return fmt.Errorf("invalid NumericDate: %w", err)
+	}
+	*d = NumericDate(value)
+	return nil
+}
+
+// Audience is the "aud" claim, which is serialized as a single string when it holds one value
+// and as an array otherwise.
+type Audience []StringOrURI
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]StringOrURI(a))
+}
+
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	// A null "aud" is an absent audience, not one holding the empty string
+	if string(b) == "null" {
+		*a = nil
+		return nil
+	}
+	var single StringOrURI
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var values []StringOrURI
+	if err := json.Unmarshal(b, &values); err != nil {
+		return fmt.Errorf("invalid audience: %w", err)
+	}
+	*a = values
+	return nil
+}
+
+// Contains reports whether aud is one of the audience values.
+func (a Audience) Contains(aud string) bool {
+	for _, value := range a {
+		if string(value) == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisteredClaims holds the registered claims of RFC 7519 §4.1. Absent dates are nil.
+type RegisteredClaims struct {
+	Issuer    StringOrURI  `json:"iss,omitempty"`
+	Subject   StringOrURI  `json:"sub,omitempty"`
+	Audience  Audience     `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+}
+
+var registeredClaimNames = []string{IssuerClaim, SubjectClaim, AudienceClaim, ExpirationTimeClaim, NotBeforeClaim, IssuedAtClaim, JWTIDClaim}
+
+// RegisteredClaims reads the registered claims of the set, validating their types.
+func (j JWTClaimsSet) RegisteredClaims() (RegisteredClaims, error) {
+	m := make(map[string]interface{})
+	for _, name := range registeredClaimNames {
+		if value, err := j.GetClaimValue(name); err == nil {
+			m[name] = value
+		}
+	}
+	var r RegisteredClaims
+	b, err := json.Marshal(m)
+	if err != nil {
+		return RegisteredClaims{}, err
+	}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return RegisteredClaims{}, err
+	}
+	return r, nil
+}
+
+// Validate checks the StringOrURI values of the registered claims.
+func (r RegisteredClaims) Validate() error {
+	values := append(Audience{r.Issuer, r.Subject}, r.Audience...)
+	for _, value := range values {
+		if err := value.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetRegisteredClaims stores the non-empty registered claims of r in the set, replacing existing values.
+// Values are stored in the form encoding/json decodes them, as ParseJWS does.
+func (j *JWTClaimsSet) SetRegisteredClaims(r RegisteredClaims) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	for _, name := range registeredClaimNames {
+		if value, ok := m[name]; ok {
+			j.SetClaimValue(name, value)
+		}
+	}
+	return nil
+}
+
+// ClaimsSet returns a claims set holding the non-empty registered claims of r.
+func (r RegisteredClaims) ClaimsSet() (JWTClaimsSet, error) {
+	var j JWTClaimsSet
+	if err := j.SetRegisteredClaims(r); err != nil {
+		return JWTClaimsSet{}, err
+	}
+	return j, nil
+}
+
+// ClaimsAs decodes the claims set of the JWT into a value of type T, usually a struct embedding
+// RegisteredClaims. Numbers decoded into interface{} fields are kept as json.Number. Integers above 2^53
+// only keep their exact value when the JWT was parsed with UseJSONNumber.
+func ClaimsAs[T any](j JWT) (T, error) {
+	var out T
+	b, err := j.payload.toJSON()
+	if err != nil {
+		return out, err
+	}
+	if err := decodeJSON(b, &out, true); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// GetClaimAs decodes the named claim into a value of type T.
+func GetClaimAs[T any](set JWTClaimsSet, name string) (T, error) {
+	var out T
+	value, err := set.GetClaimValue(name)
+	if err != nil {
+		return out, err
+	}
+	if v, ok := value.(T); ok {
+		return v, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return out, err
+	}
+	if err := decodeJSON(b, &out, true); err != nil {
+		return out, fmt.Errorf("claim %s: %w", name, err)
+	}
+	return out, nil
+}
+
+// EncodeClaims builds a claims set from the JSON object encoding of v. Numbers are stored as
+// json.Number so that the signed payload carries their exact value.
+func EncodeClaims(v interface{}) (JWTClaimsSet, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return JWTClaimsSet{}, err
+	}
+	return decodeClaims(b, true)
+}
+
+// decodeClaims decodes a JSON object into a claims set. Numbers become float64 values, as with
+// json.Unmarshal, unless useNumber keeps them as json.Number.
+func decodeClaims(b []byte, useNumber bool) (JWTClaimsSet, error) {
+	var m map[string]interface{}
+	if err := decodeJSON(b, &m, useNumber); err != nil {
+		return JWTClaimsSet{}, err
+	}
+	if m == nil {
+		return JWTClaimsSet{}, fmt.Errorf("claims set is not a JSON object")
+	}
+	return NewJWTClaimsSet(m), nil
+}
+
+// decodeJSON behaves like json.Unmarshal, optionally decoding numbers as json.Number.
+func decodeJSON(b []byte, v interface{}, useNumber bool) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if useNumber {
+		dec.UseNumber()
+	}
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("invalid JSON: unexpected data after top-level value")
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package hermes
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumericDate(t *testing.T) {
+	d := NewNumericDate(time.Unix(1300819380, 500000000))
+	assert.Equal(t, NumericDate(1300819380.5), d)
+	assert.Equal(t, time.Unix(1300819380, 500000000), d.Time())
+
+	b, err := json.Marshal(NumericDate(1300819380))
+	assert.NoError(t, err)
+	assert.Equal(t, "1300819380", string(b))
+	b, err = json.Marshal(d)
+	assert.NoError(t, err)
+	assert.Equal(t, "1300819380.5", string(b))
+
+	assert.Equal(t, NumericDate(1300819380), NewNumericDateFromUnix(1300819380))
+	assert.Equal(t, int64(1300819380), d.Unix())
+	assert.Equal(t, int64(1300819440), NewNumericDateFromUnix(1300819380).Unix()+60)
+
+	var parsed NumericDate
+	assert.NoError(t, json.Unmarshal([]byte("1300819380.25"), &parsed))
+	assert.Equal(t, time.Unix(1300819380, 250000000), parsed.Time())
+	assert.Error(t, json.Unmarshal([]byte(`"1300819380"`), &parsed))
+}
+
+func TestStringOrURI(t *testing.T) {
+	for _, valid := range []StringOrURI{"joe", "https://example.com", "urn:example:issuer", ""} {
+		assert.NoError(t, valid.Validate(), valid)
+	}
+	for _, invalid := range []StringOrURI{"1joe:x", ":joe", "http://[::1"} {
+		assert.Error(t, invalid.Validate(), invalid)
+	}
+	var s StringOrURI
+	assert.Error(t, json.Unmarshal([]byte(`":joe"`), &s))
+	assert.NoError(t, json.Unmarshal([]byte(`"https://example.com"`), &s))
+	assert.Equal(t, StringOrURI("https://example.com"), s)
+}
+
+func TestAudience(t *testing.T) {
+	b, err := json.Marshal(Audience{"api"})
+	assert.NoError(t, err)
+	assert.Equal(t, `"api"`, string(b))
+	b, err = json.Marshal(Audience{"api", "web"})
+	assert.NoError(t, err)
+	assert.Equal(t, `["api","web"]`, string(b))
+
+	var aud Audience
+	assert.NoError(t, json.Unmarshal([]byte(`"api"`), &aud))
+	assert.Equal(t, Audience{"api"}, aud)
+	assert.NoError(t, json.Unmarshal([]byte(`["api","web"]`), &aud))
+	assert.Equal(t, Audience{"api", "web"}, aud)
+	assert.True(t, aud.Contains("web"))
+	assert.False(t, aud.Contains("admin"))
+	assert.Error(t, json.Unmarshal([]byte(`["api",1]`), &aud))
+	assert.NoError(t, json.Unmarshal([]byte(`null`), &aud))
+	assert.Nil(t, aud)
+
+	var r RegisteredClaims
+	assert.NoError(t, json.Unmarshal([]byte(`{"aud":null}`), &r))
+	assert.Nil(t, r.Audience)
+	assert.False(t, r.Audience.Contains(""))
+}
+
+func TestRegisteredClaims(t *testing.T) {
+	claims := NewJWTClaimsSet(map[string]interface{}{
+		"iss":  "joe",
+		"aud":  []interface{}{"api", "web"},
+		"exp":  float64(1300819380),
+		"iat":  1300819320.5,
+		"name": "John Doe",
+	})
+	r, err := claims.RegisteredClaims()
+	assert.NoError(t, err)
+	assert.Equal(t, StringOrURI("joe"), r.Issuer)
+	assert.Equal(t, Audience{"api", "web"}, r.Audience)
+	assert.Equal(t, time.Unix(1300819380, 0), r.ExpiresAt.Time())
+	assert.Equal(t, time.Unix(1300819320, 500000000), r.IssuedAt.Time())
+	assert.Nil(t, r.NotBefore)
+
+	_, err = NewJWTClaimsSet(map[string]interface{}{"exp": "tomorrow"}).RegisteredClaims()
+	assert.Error(t, err)
+	_, err = NewJWTClaimsSet(map[string]interface{}{"sub": ":a"}).RegisteredClaims()
+	assert.Error(t, err)
+
+	exp := NewNumericDate(time.Unix(1300819380, 0))
+	set, err := RegisteredClaims{Subject: "1234567890", Audience: Audience{"api"}, ExpiresAt: &exp}.ClaimsSet()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"sub", "aud", "exp"}, set.GetClaimNames())
+	aud, _ := set.GetClaimValue("aud")
+	assert.Equal(t, "api", aud)
+	b, err := set.toJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"sub":"1234567890","aud":"api","exp":1300819380}`, string(b))
+	assert.NoError(t, NewValidator(WithAudience("api"), WithClock(func() time.Time { return time.Unix(1300819000, 0) })).Validate(set))
+
+	_, err = RegisteredClaims{Issuer: ":a"}.ClaimsSet()
+	assert.Error(t, err)
+}
+
+type profileClaims struct {
+	RegisteredClaims
+	Name   string                 `json:"name"`
+	Admin  bool                   `json:"admin"`
+	UserID uint64                 `json:"user_id"`
+	Extra  map[string]interface{} `json:"extra"`
+}
+
+func TestClaimsAs(t *testing.T) {
+	compact, _, err := NewBuilder().Encode(profileClaims{
+		RegisteredClaims: RegisteredClaims{Subject: "1234567890", Audience: Audience{"api"}},
+		Name:             "John Doe",
+		Admin:            true,
+		UserID:           18446744073709551615,
+		Extra:            map[string]interface{}{"big": json.Number("9007199254740993")},
+	}).Sign("HS256", []byte("key"))
+	assert.NoError(t, err)
+	jwt, err := ParseJWS(compact, UseJSONNumber())
+	assert.NoError(t, err)
+
+	profile, err := ClaimsAs[profileClaims](jwt)
+	assert.NoError(t, err)
+	assert.Equal(t, StringOrURI("1234567890"), profile.Subject)
+	assert.Equal(t, Audience{"api"}, profile.Audience)
+	assert.Equal(t, "John Doe", profile.Name)
+	assert.True(t, profile.Admin)
+	assert.Equal(t, uint64(18446744073709551615), profile.UserID)
+	assert.Equal(t, json.Number("9007199254740993"), profile.Extra["big"])
+
+	// Large integers survive a parse and re-encode
+	userID, err := jwt.Claims().GetClaimValue("user_id")
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("18446744073709551615"), userID)
+	b, err := jwt.Claims().toJSON()
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"user_id":18446744073709551615`)
+
+	_, err = ClaimsAs[struct {
+		Name int `json:"name"`
+	}](jwt)
+	assert.Error(t, err)
+}
+
+func TestGetClaimAs(t *testing.T) {
+	set, err := decodeClaims([]byte(`{"name":"John Doe","n":42,"aud":["api","web"],"address":{"city":"Springfield"}}`), false)
+	assert.NoError(t, err)
+	name, err := GetClaimAs[string](set, "name")
+	assert.NoError(t, err)
+	assert.Equal(t, "John Doe", name)
+	n, err := GetClaimAs[int64](set, "n")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), n)
+	aud, err := GetClaimAs[Audience](set, "aud")
+	assert.NoError(t, err)
+	assert.Equal(t, Audience{"api", "web"}, aud)
+	address, err := GetClaimAs[struct{ City string }](set, "address")
+	assert.NoError(t, err)
+	assert.Equal(t, "Springfield", address.City)
+
+	_, err = GetClaimAs[int](set, "name")
+	assert.Error(t, err)
+	_, err = GetClaimAs[string](set, "missing")
+	assert.Error(t, err)
+}
+
+func TestDecodeClaims(t *testing.T) {
+	_, err := decodeClaims([]byte(`{"sub":"a"} {}`), false)
+	assert.Error(t, err)
+	_, err = decodeClaims([]byte(`null`), false)
+	assert.Error(t, err)
+	_, err = EncodeClaims([]string{"a"})
+	assert.Error(t, err)
+}
+
+func TestParsedNumericClaims(t *testing.T) {
+	compact, _, err := NewBuilder().Claim("exp", 1300819380).Claim("n", 9007199254740993).Sign("HS256", []byte("key"))
+	assert.NoError(t, err)
+
+	// Numeric claims decode as float64 by default
+	jwt, err := ParseJWS(compact)
+	assert.NoError(t, err)
+	value, err := jwt.Claims().GetClaimValue("exp")
+	assert.NoError(t, err)
+	exp, ok := value.(float64)
+	assert.True(t, ok)
+	assert.Equal(t, float64(1300819380), exp)
+
+	// UseJSONNumber keeps the exact value of large integers
+	jwt, err = ParseJWS(compact, UseJSONNumber())
+	assert.NoError(t, err)
+	value, err = jwt.Claims().GetClaimValue("n")
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("9007199254740993"), value)
+}
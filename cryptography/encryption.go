@@ -0,0 +1,174 @@
+package cryptography
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"hash"
+)
+
+// gcmIVSize is the 96-bit IV length required for AES GCM by RFC 7518 §5.3.
+const gcmIVSize = 12
+
+// AESCBCHMACEncrypt encrypts the plaintext using the AES_CBC_HMAC_SHA2 composite algorithm of RFC 7518 §5.2,
+// returning the IV, ciphertext and authentication tag. The key length selects the variant: 32 bytes for
+// A128CBC-HS256, 48 bytes for A192CBC-HS384 and 64 bytes for A256CBC-HS512.
+func AESCBCHMACEncrypt(key []byte, plaintext []byte, aad []byte) ([]byte, []byte, []byte, error) {
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, nil, nil, err
+	}
+	ciphertext, tag, err := aesCBCHMACEncrypt(key, iv, plaintext, aad)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return iv, ciphertext, tag, nil
+}
+
+// AESCBCHMACDecrypt verifies the authentication tag and decrypts the ciphertext using the AES_CBC_HMAC_SHA2 composite algorithm.
+func AESCBCHMACDecrypt(key []byte, iv []byte, ciphertext []byte, tag []byte, aad []byte) ([]byte, error) {
+	h, err := cbcHMACHash(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid IV length")
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("invalid ciphertext length")
+	}
+	macKey, encKey := key[:len(key)/2], key[len(key)/2:]
+	expectedTag := cbcHMACTag(h, macKey, aad, iv, ciphertext)
+	if !hmac.Equal(tag, expectedTag) {
+		return nil, fmt.Errorf("invalid authentication tag")
+	}
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+	return pkcs7Unpad(plaintext)
+}
+
+// AESGCMEncrypt encrypts the plaintext using AES GCM, returning the IV, ciphertext and authentication tag.
+// The key length selects A128GCM, A192GCM or A256GCM.
+func AESGCMEncrypt(key []byte, plaintext []byte, aad []byte) ([]byte, []byte, []byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	iv := make([]byte, gcmIVSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, nil, nil, err
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, aad)
+	split := len(sealed) - gcm.Overhead()
+	return iv, sealed[:split], sealed[split:], nil
+}
+
+// AESGCMDecrypt verifies the authentication tag and decrypts the ciphertext using AES GCM.
+func AESGCMDecrypt(key []byte, iv []byte, ciphertext []byte, tag []byte, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != gcmIVSize {
+		return nil, fmt.Errorf("invalid IV length")
+	}
+	if len(tag) != gcm.Overhead() {
+		return nil, fmt.Errorf("invalid authentication tag")
+	}
+	sealed := make([]byte, 0, len(ciphertext)+len(tag))
+	sealed = append(append(sealed, ciphertext...), tag...)
+	plaintext, err := gcm.Open(nil, iv, sealed, aad)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authentication tag")
+	}
+	return plaintext, nil
+}
+
+func aesCBCHMACEncrypt(key []byte, iv []byte, plaintext []byte, aad []byte) ([]byte, []byte, error) {
+	h, err := cbcHMACHash(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	macKey, encKey := key[:len(key)/2], key[len(key)/2:]
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext := pkcs7Pad(plaintext, aes.BlockSize)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+	return ciphertext, cbcHMACTag(h, macKey, aad, iv, ciphertext), nil
+}
+
+// cbcHMACHash returns the HMAC hash for an AES_CBC_HMAC_SHA2 key of the given length.
+func cbcHMACHash(key []byte) (func() hash.Hash, error) {
+	switch len(key) {
+	case 32:
+		return sha256.New, nil
+	case 48:
+		return sha512.New384, nil
+	case 64:
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("invalid key length")
+	}
+}
+
+// cbcHMACTag computes HMAC(MAC_KEY, A || IV || E || AL) truncated to the MAC key length.
+func cbcHMACTag(h func() hash.Hash, macKey []byte, aad []byte, iv []byte, ciphertext []byte) []byte {
+	al := make([]byte, 8)
+	binary.BigEndian.PutUint64(al, uint64(len(aad))*8)
+	mac := hmac.New(h, macKey)
+	mac.Write(aad)
+	mac.Write(iv)
+	mac.Write(ciphertext)
+	mac.Write(al)
+	return mac.Sum(nil)[:len(macKey)]
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("invalid key length")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func pkcs7Pad(b []byte, blockSize int) []byte {
+	n := blockSize - len(b)%blockSize
+	out := make([]byte, len(b)+n)
+	copy(out, b)
+	for i := len(b); i < len(out); i++ {
+		out[i] = byte(n)
+	}
+	return out
+}
+
+// pkcs7Unpad removes the padding in constant time with respect to the padding contents.
+func pkcs7Unpad(b []byte) ([]byte, error) {
+	n := int(b[len(b)-1])
+	valid := subtle.ConstantTimeLessOrEq(1, n) & subtle.ConstantTimeLessOrEq(n, aes.BlockSize)
+	for i := 1; i <= aes.BlockSize; i++ {
+		inPadding := subtle.ConstantTimeLessOrEq(i, n)
+		matches := subtle.ConstantTimeByteEq(b[len(b)-i], byte(n))
+		valid &= subtle.ConstantTimeSelect(inPadding, matches, 1)
+	}
+	if valid != 1 {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	return b[:len(b)-n], nil
+}
@@ -0,0 +1,118 @@
+package cryptography
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func sequence(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+func TestAESCBCHMAC(t *testing.T) {
+	// RFC 7518 Appendix B
+	plaintext := []byte("A cipher system must not be required to be secret, and it must be able to fall into the hands of the enemy without inconvenience")
+	aad := []byte("The second principle of Auguste Kerckhoffs")
+	iv, _ := hex.DecodeString("1af38c2dc2b96ffdd86694092341bc04")
+	tests := []struct {
+		keySize     int
+		expectedHEX string
+	}{
+		{keySize: 32, expectedHEX: "652c3fa36b0a7c5b3219fab3a30bc1c4"},
+		{keySize: 48, expectedHEX: "8490ac0e58949bfe51875d733f93ac2075168039ccc733d7"},
+		{keySize: 64, expectedHEX: "4dd3b4c088a7f45c216839645b2012bf2e6269a8c56a816dbc1b267761955bc5"},
+	}
+	for _, test := range tests {
+		key := sequence(test.keySize)
+		ciphertext, tag, err := aesCBCHMACEncrypt(key, iv, plaintext, aad)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if hx := hex.EncodeToString(tag); hx != test.expectedHEX {
+			t.Errorf("expected %s, got %s", test.expectedHEX, hx)
+		}
+		decrypted, err := AESCBCHMACDecrypt(key, iv, ciphertext, tag, aad)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("expected %s, got %s", plaintext, decrypted)
+		}
+		tag[0] ^= 1
+		if _, err := AESCBCHMACDecrypt(key, iv, ciphertext, tag, aad); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	}
+}
+
+func TestAESCBCHMACRoundTrip(t *testing.T) {
+	key := sequence(32)
+	for _, plaintext := range [][]byte{{}, []byte("0123456789abcdef"), []byte("Live long and prosper.")} {
+		iv, ciphertext, tag, err := AESCBCHMACEncrypt(key, plaintext, []byte("aad"))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		decrypted, err := AESCBCHMACDecrypt(key, iv, ciphertext, tag, []byte("aad"))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("expected %s, got %s", plaintext, decrypted)
+		}
+		if _, err := AESCBCHMACDecrypt(key, iv, ciphertext, tag, []byte("other")); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	}
+	if _, _, _, err := AESCBCHMACEncrypt(sequence(16), []byte("plaintext"), nil); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestAESGCM(t *testing.T) {
+	plaintext := []byte("Live long and prosper.")
+	aad := []byte("aad")
+	for _, keySize := range []int{16, 24, 32} {
+		key := sequence(keySize)
+		iv, ciphertext, tag, err := AESGCMEncrypt(key, plaintext, aad)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(iv) != 12 || len(tag) != 16 {
+			t.Errorf("unexpected IV or tag length: %d, %d", len(iv), len(tag))
+		}
+		decrypted, err := AESGCMDecrypt(key, iv, ciphertext, tag, aad)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("expected %s, got %s", plaintext, decrypted)
+		}
+		ciphertext[0] ^= 1
+		if _, err := AESGCMDecrypt(key, iv, ciphertext, tag, aad); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	}
+	if _, _, _, err := AESGCMEncrypt(sequence(64), plaintext, aad); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestPKCS7Unpad(t *testing.T) {
+	padded := pkcs7Pad([]byte("abc"), 16)
+	unpadded, err := pkcs7Unpad(padded)
+	if err != nil || string(unpadded) != "abc" {
+		t.Errorf("expected abc, got %s (%v)", unpadded, err)
+	}
+	padded[10] = 0
+	if _, err := pkcs7Unpad(padded); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if _, err := pkcs7Unpad(make([]byte, 16)); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
@@ -0,0 +1,320 @@
+package cryptography
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// RSAOAEPEncrypt encrypts a content encryption key using RSAES-OAEP with the given hash for both OAEP and MGF1.
+func RSAOAEPEncrypt(hash crypto.Hash, key interface{}, cek []byte) ([]byte, error) {
+	if !hash.Available() {
+		return nil, fmt.Errorf("hash function is not available")
+	}
+	rsaPublicKey, err := rsaKeyManagementPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return rsa.EncryptOAEP(hash.New(), rand.Reader, rsaPublicKey, cek, nil)
+}
+
+// RSAOAEPDecrypt decrypts a content encryption key using RSAES-OAEP with the given hash for both OAEP and MGF1.
+func RSAOAEPDecrypt(hash crypto.Hash, key interface{}, encryptedKey []byte) ([]byte, error) {
+	if !hash.Available() {
+		return nil, fmt.Errorf("hash function is not available")
+	}
+	rsaPrivateKey, err := rsaKeyManagementPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := rsa.DecryptOAEP(hash.New(), nil, rsaPrivateKey, encryptedKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted key")
+	}
+	return cek, nil
+}
+
+// RSAPKCS1v15Encrypt encrypts a content encryption key using RSAES-PKCS1-v1_5.
+func RSAPKCS1v15Encrypt(key interface{}, cek []byte) ([]byte, error) {
+	rsaPublicKey, err := rsaKeyManagementPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return rsa.EncryptPKCS1v15(rand.Reader, rsaPublicKey, cek)
+}
+
+// RSAPKCS1v15Decrypt decrypts a content encryption key of cekSize bytes using RSAES-PKCS1-v1_5.
+// To avoid a padding oracle (RFC 7516 §11.5) a malformed encrypted key is not reported:
+// a random key is returned instead so that the failure surfaces as an invalid authentication tag.
+func RSAPKCS1v15Decrypt(key interface{}, encryptedKey []byte, cekSize int) ([]byte, error) {
+	rsaPrivateKey, err := rsaKeyManagementPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	cek := make([]byte, cekSize)
+	if _, err := rand.Read(cek); err != nil {
+		return nil, err
+	}
+	if err := rsa.DecryptPKCS1v15SessionKey(nil, rsaPrivateKey, encryptedKey, cek); err != nil {
+		return nil, err
+	}
+	return cek, nil
+}
+
+func rsaKeyManagementPublicKey(key interface{}) (*rsa.PublicKey, error) {
+	rsaPublicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("key must be a *rsa.PublicKey")
+	}
+	if rsaPublicKey.N.BitLen() < rsaMinimumKeySize {
+		return nil, fmt.Errorf("key size must be at least %d bits", rsaMinimumKeySize)
+	}
+	return rsaPublicKey, nil
+}
+
+func rsaKeyManagementPrivateKey(key interface{}) (*rsa.PrivateKey, error) {
+	rsaPrivateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key must be a *rsa.PrivateKey")
+	}
+	if rsaPrivateKey.N.BitLen() < rsaMinimumKeySize {
+		return nil, fmt.Errorf("key size must be at least %d bits", rsaMinimumKeySize)
+	}
+	return rsaPrivateKey, nil
+}
+
+// aesKeyWrapIV is the default initial value of RFC 3394 §2.2.3.1.
+var aesKeyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// AESKeyWrap wraps a content encryption key with the key encryption key using the RFC 3394 AES Key Wrap algorithm.
+func AESKeyWrap(kek []byte, cek []byte) ([]byte, error) {
+	if len(cek) < 16 || len(cek)%8 != 0 {
+		return nil, fmt.Errorf("key to wrap must be a multiple of 8 bytes and at least 16 bytes")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(cek) / 8
+	out := make([]byte, 8+len(cek))
+	copy(out, aesKeyWrapIV)
+	copy(out[8:], cek)
+	b := make([]byte, aes.BlockSize)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(b, out[:8])
+			copy(b[8:], out[i*8:(i+1)*8])
+			block.Encrypt(b, b)
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(out[i*8:(i+1)*8], b[8:])
+		}
+	}
+	return out, nil
+}
+
+// AESKeyUnwrap unwraps a content encryption key with the key encryption key using the RFC 3394 AES Key Wrap algorithm.
+func AESKeyUnwrap(kek []byte, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, fmt.Errorf("invalid wrapped key length")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(wrapped)/8 - 1
+	a := make([]byte, 8)
+	copy(a, wrapped[:8])
+	r := make([]byte, len(wrapped)-8)
+	copy(r, wrapped[8:])
+	b := make([]byte, aes.BlockSize)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(a)^t)
+			copy(b[8:], r[(i-1)*8:i*8])
+			block.Decrypt(b, b)
+			copy(a, b[:8])
+			copy(r[(i-1)*8:i*8], b[8:])
+		}
+	}
+	if subtle.ConstantTimeCompare(a, aesKeyWrapIV) != 1 {
+		return nil, fmt.Errorf("invalid wrapped key")
+	}
+	return r, nil
+}
+
+// AESGCMKeyWrap encrypts a content encryption key with AES GCM, returning the IV, encrypted key and authentication tag.
+func AESGCMKeyWrap(kek []byte, cek []byte) ([]byte, []byte, []byte, error) {
+	return AESGCMEncrypt(kek, cek, nil)
+}
+
+// AESGCMKeyUnwrap decrypts a content encryption key with AES GCM using the IV and authentication tag of the JWE header.
+func AESGCMKeyUnwrap(kek []byte, iv []byte, encryptedKey []byte, tag []byte) ([]byte, error) {
+	cek, err := AESGCMDecrypt(kek, iv, encryptedKey, tag, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted key")
+	}
+	return cek, nil
+}
+
+// ECDHESGenerate generates an ephemeral key pair on the curve of the recipient public key, returning the
+// ephemeral public key and the shared secret Z. The recipient key may be a *ecdsa.PublicKey or a *ecdh.PublicKey.
+// The ephemeral public key is a *ecdsa.PublicKey for NIST curves and a *ecdh.PublicKey for X25519.
+func ECDHESGenerate(key interface{}) (interface{}, []byte, error) {
+	recipient, err := ecdhPublicKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	ephemeral, err := recipient.Curve().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	z, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return nil, nil, err
+	}
+	if recipient.Curve() == ecdh.X25519() {
+		return ephemeral.PublicKey(), z, nil
+	}
+	ephemeralPublicKey, err := ecdsaPublicKey(ephemeral.PublicKey())
+	if err != nil {
+		return nil, nil, err
+	}
+	return ephemeralPublicKey, z, nil
+}
+
+// ECDHESAgree computes the shared secret Z from the recipient private key and the ephemeral public key of the
+// sender, rejecting ephemeral keys that are not valid points on the curve of the recipient key.
+func ECDHESAgree(key interface{}, ephemeral interface{}) ([]byte, error) {
+	var recipient *ecdh.PrivateKey
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		var err error
+		if recipient, err = k.ECDH(); err != nil {
+			return nil, err
+		}
+	case *ecdh.PrivateKey:
+		recipient = k
+	default:
+		return nil, fmt.Errorf("key must be a *ecdsa.PrivateKey or *ecdh.PrivateKey")
+	}
+	sender, err := ecdhPublicKey(ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral public key: %w", err)
+	}
+	if sender.Curve() != recipient.Curve() {
+		return nil, fmt.Errorf("ephemeral public key curve does not match key")
+	}
+	return recipient.ECDH(sender)
+}
+
+// ConcatKDF derives keySize bytes from the shared secret Z using the Concat KDF of NIST SP 800-56A
+// with the OtherInfo structure of RFC 7518 §4.6.2.
+func ConcatKDF(hash crypto.Hash, z []byte, algorithmID []byte, partyUInfo []byte, partyVInfo []byte, keySize int) []byte {
+	otherInfo := make([]byte, 0, 16+len(algorithmID)+len(partyUInfo)+len(partyVInfo))
+	for _, info := range [][]byte{algorithmID, partyUInfo, partyVInfo} {
+		otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(len(info)))
+		otherInfo = append(otherInfo, info...)
+	}
+	otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(keySize*8))
+	out := make([]byte, 0, keySize+hash.Size())
+	for counter := uint32(1); len(out) < keySize; counter++ {
+		h := hash.New()
+		binary.Write(h, binary.BigEndian, counter)
+		h.Write(z)
+		h.Write(otherInfo)
+		out = h.Sum(out)
+	}
+	return out[:keySize]
+}
+
+// ecdhPublicKey converts a public key to its crypto/ecdh form, validating that the point is on the curve.
+func ecdhPublicKey(key interface{}) (*ecdh.PublicKey, error) {
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return k.ECDH()
+	case *ecdh.PublicKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("key must be a *ecdsa.PublicKey or *ecdh.PublicKey")
+	}
+}
+
+// ecdsaPublicKey converts a crypto/ecdh NIST public key to its crypto/ecdsa form.
+func ecdsaPublicKey(key *ecdh.PublicKey) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch key.Curve() {
+	case ecdh.P256():
+		curve = elliptic.P256()
+	case ecdh.P384():
+		curve = elliptic.P384()
+	case ecdh.P521():
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve")
+	}
+	point := key.Bytes()
+	size := (curve.Params().BitSize + 7) / 8
+	if len(point) != 1+2*size || point[0] != 4 {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(point[1 : 1+size]),
+		Y:     new(big.Int).SetBytes(point[1+size:]),
+	}, nil
+}
+
+// PBES2DeriveKey derives a key encryption key of keySize bytes from a password using PBKDF2 with HMAC and the
+// given hash, salted with the "alg" value and the "p2s" salt input as defined in RFC 7518 §4.8.1.1.
+func PBES2DeriveKey(hash crypto.Hash, password []byte, algorithm string, saltInput []byte, count int, keySize int) ([]byte, error) {
+	if !hash.Available() {
+		return nil, fmt.Errorf("hash function is not available")
+	}
+	if len(password) == 0 {
+		return nil, fmt.Errorf("password must not be empty")
+	}
+	if len(saltInput) < 8 {
+		return nil, fmt.Errorf("salt input must be at least 8 bytes")
+	}
+	if count < 1 {
+		return nil, fmt.Errorf("iteration count must be positive")
+	}
+	salt := make([]byte, 0, len(algorithm)+1+len(saltInput))
+	salt = append(append(append(salt, algorithm...), 0), saltInput...)
+	return pbkdf2(hash, password, salt, count, keySize), nil
+}
+
+// pbkdf2 implements PBKDF2 with HMAC as the pseudorandom function, as defined in RFC 8018 §5.2.
+func pbkdf2(hash crypto.Hash, password []byte, salt []byte, count int, keySize int) []byte {
+	prf := hmac.New(hash.New, password)
+	out := make([]byte, 0, keySize+prf.Size())
+	u := make([]byte, prf.Size())
+	t := make([]byte, prf.Size())
+	for block := uint32(1); len(out) < keySize; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.Write(prf, binary.BigEndian, block)
+		u = prf.Sum(u[:0])
+		copy(t, u)
+		for i := 1; i < count; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			subtle.XORBytes(t, t, u)
+		}
+		out = append(out, t...)
+	}
+	return out[:keySize]
+}
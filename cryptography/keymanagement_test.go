@@ -0,0 +1,272 @@
+package cryptography
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"testing"
+)
+
+func TestRSAOAEP(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cek := sequence(32)
+	for _, hash := range []crypto.Hash{crypto.SHA1, crypto.SHA256} {
+		encryptedKey, err := RSAOAEPEncrypt(hash, &private.PublicKey, cek)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		decrypted, err := RSAOAEPDecrypt(hash, private, encryptedKey)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(decrypted, cek) {
+			t.Errorf("expected %x, got %x", cek, decrypted)
+		}
+		encryptedKey[0] ^= 1
+		if _, err := RSAOAEPDecrypt(hash, private, encryptedKey); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	}
+}
+
+func TestRSAPKCS1v15(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cek := sequence(32)
+	encryptedKey, err := RSAPKCS1v15Encrypt(&private.PublicKey, cek)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	decrypted, err := RSAPKCS1v15Decrypt(private, encryptedKey, 32)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(decrypted, cek) {
+		t.Errorf("expected %x, got %x", cek, decrypted)
+	}
+
+	// A malformed encrypted key yields a random key instead of an error
+	encryptedKey[0] ^= 1
+	decrypted, err = RSAPKCS1v15Decrypt(private, encryptedKey, 32)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(decrypted) != 32 || bytes.Equal(decrypted, cek) {
+		t.Errorf("expected a random 32 byte key, got %x", decrypted)
+	}
+
+	// A CEK of unexpected length also yields a random key
+	encryptedKey, _ = RSAPKCS1v15Encrypt(&private.PublicKey, cek)
+	decrypted, err = RSAPKCS1v15Decrypt(private, encryptedKey, 16)
+	if err != nil || len(decrypted) != 16 {
+		t.Errorf("expected a random 16 byte key, got %x (%v)", decrypted, err)
+	}
+}
+
+func TestRSAKeyManagementMinimumKeySize(t *testing.T) {
+	block, _ := pem.Decode([]byte(RSA_PRIVATE_KEY))
+	private, _ := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if _, err := RSAOAEPEncrypt(crypto.SHA256, &private.PublicKey, sequence(32)); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if _, err := RSAPKCS1v15Decrypt(private, []byte("key"), 32); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if _, err := RSAOAEPEncrypt(crypto.SHA256, []byte("key"), sequence(32)); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestAESKeyWrap(t *testing.T) {
+	// RFC 3394 Sections 4.1 and 4.6
+	tests := []struct {
+		kek         string
+		cek         string
+		expectedHEX string
+	}{
+		{kek: "000102030405060708090a0b0c0d0e0f", cek: "00112233445566778899aabbccddeeff", expectedHEX: "1fa68b0a8112b447aef34bd8fb5a7b829d3e862371d2cfe5"},
+		{kek: "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f", cek: "00112233445566778899aabbccddeeff000102030405060708090a0b0c0d0e0f", expectedHEX: "28c9f404c4b810f4cbccb35cfb87f8263f5786e2d80ed326cbc7f0e71a99f43bfb988b9b7a02dd21"},
+	}
+	for _, test := range tests {
+		kek, _ := hex.DecodeString(test.kek)
+		cek, _ := hex.DecodeString(test.cek)
+		wrapped, err := AESKeyWrap(kek, cek)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if hx := hex.EncodeToString(wrapped); hx != test.expectedHEX {
+			t.Errorf("expected %s, got %s", test.expectedHEX, hx)
+		}
+		unwrapped, err := AESKeyUnwrap(kek, wrapped)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(unwrapped, cek) {
+			t.Errorf("expected %x, got %x", cek, unwrapped)
+		}
+		wrapped[len(wrapped)-1] ^= 1
+		if _, err := AESKeyUnwrap(kek, wrapped); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	}
+	if _, err := AESKeyWrap(sequence(16), sequence(12)); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestAESGCMKeyWrap(t *testing.T) {
+	kek := sequence(32)
+	cek := sequence(64)
+	iv, encryptedKey, tag, err := AESGCMKeyWrap(kek, cek)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	unwrapped, err := AESGCMKeyUnwrap(kek, iv, encryptedKey, tag)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(unwrapped, cek) {
+		t.Errorf("expected %x, got %x", cek, unwrapped)
+	}
+	tag[0] ^= 1
+	if _, err := AESGCMKeyUnwrap(kek, iv, encryptedKey, tag); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestConcatKDF(t *testing.T) {
+	// RFC 7518 Appendix C
+	decode := func(s string) []byte {
+		b, _ := base64.RawURLEncoding.DecodeString(s)
+		return b
+	}
+	ephemeral := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(decode("gI0GAILBdu7T53akrFmMyGcsF3n5dO7MmwNBHKW5SV0")),
+		Y:     new(big.Int).SetBytes(decode("SLW_xSffzlPWrHEVI30DHM_4egVwt3NQqeUD7nMFpps")),
+	}
+	bob := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(decode("weNJy2HscCSM6AEDTDg04biOvhFhyyWvOHQfeF_PxMQ")),
+			Y:     new(big.Int).SetBytes(decode("e8lnCO-AlStT-NJVX-crhB7QRYhiix03illJOVAOyck")),
+		},
+		D: new(big.Int).SetBytes(decode("VEmDZpDXXK8p8N0Cndsxs924q6nS1RXFASRl6BfUqdw")),
+	}
+	z, err := ECDHESAgree(bob, ephemeral)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	key := ConcatKDF(crypto.SHA256, z, []byte("A128GCM"), []byte("Alice"), []byte("Bob"), 16)
+	if b64 := base64.RawURLEncoding.EncodeToString(key); b64 != "VqqN6vgjbSBcIijNcacQGg" {
+		t.Errorf("expected %s, got %s", "VqqN6vgjbSBcIijNcacQGg", b64)
+	}
+	if long := ConcatKDF(crypto.SHA256, z, []byte("A256CBC-HS512"), nil, nil, 64); len(long) != 64 {
+		t.Errorf("expected 64 bytes, got %d", len(long))
+	}
+}
+
+func TestECDHESX25519(t *testing.T) {
+	// RFC 8037 Appendix A.6
+	decode := func(s string) []byte {
+		b, _ := base64.RawURLEncoding.DecodeString(s)
+		return b
+	}
+	bob, _ := ecdh.X25519().NewPublicKey(decode("3p7bfXt9wbTTW2HC7OQ1Nz-DQ8hbeGdNrfx-FG-IK08"))
+	ephemeral, _ := ecdh.X25519().NewPrivateKey(decode("dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo"))
+	z, err := ECDHESAgree(ephemeral, bob)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b64 := base64.RawURLEncoding.EncodeToString(z); b64 != "Sl2dW6TOLeFyjjv0gDUPJeB-IclH0Z4zdvCbPB4WF0I" {
+		t.Errorf("expected %s, got %s", "Sl2dW6TOLeFyjjv0gDUPJeB-IclH0Z4zdvCbPB4WF0I", b64)
+	}
+}
+
+func TestECDHES(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		private, _ := ecdsa.GenerateKey(curve, rand.Reader)
+		ephemeral, z, err := ECDHESGenerate(&private.PublicKey)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		agreed, err := ECDHESAgree(private, ephemeral)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(z, agreed) {
+			t.Errorf("expected %x, got %x", z, agreed)
+		}
+	}
+
+	// Points off the curve and keys on another curve are rejected
+	private, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	invalid := &ecdsa.PublicKey{Curve: elliptic.P256(), X: big.NewInt(1), Y: big.NewInt(1)}
+	if _, err := ECDHESAgree(private, invalid); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	other, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if _, err := ECDHESAgree(private, &other.PublicKey); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	x25519, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	if _, err := ECDHESAgree(private, x25519.PublicKey()); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestPBKDF2(t *testing.T) {
+	// RFC 6070 Section 2
+	tests := []struct {
+		count       int
+		expectedHEX string
+	}{
+		{count: 1, expectedHEX: "0c60c80f961f0e71f3a9b524af6012062fe037a6"},
+		{count: 2, expectedHEX: "ea6c014dc72d6f8ccd1ed92ace1d41f0d8de8957"},
+		{count: 4096, expectedHEX: "4b007901b765489abead49d926f721d065a429c1"},
+	}
+	for _, test := range tests {
+		key := pbkdf2(crypto.SHA1, []byte("password"), []byte("salt"), test.count, 20)
+		if hx := hex.EncodeToString(key); hx != test.expectedHEX {
+			t.Errorf("expected %s, got %s", test.expectedHEX, hx)
+		}
+	}
+	long := pbkdf2(crypto.SHA1, []byte("passwordPASSWORDpassword"), []byte("saltSALTsaltSALTsaltSALTsaltSALTsalt"), 4096, 25)
+	if hx := hex.EncodeToString(long); hx != "3d2eec4fe41c849b80c8d83662c0e44a8b291a964cf2f07038" {
+		t.Errorf("expected %s, got %s", "3d2eec4fe41c849b80c8d83662c0e44a8b291a964cf2f07038", hx)
+	}
+}
+
+func TestPBES2DeriveKey(t *testing.T) {
+	// RFC 7517 Appendix C.3 and C.4
+	password := []byte("Thus from my lips, by yours, my sin is purged.")
+	p2s := []byte{217, 96, 147, 112, 150, 117, 70, 247, 127, 8, 155, 137, 174, 42, 80, 215}
+	key, err := PBES2DeriveKey(crypto.SHA256, password, "PBES2-HS256+A128KW", p2s, 4096, 16)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	expected := []byte{110, 171, 169, 92, 129, 92, 109, 117, 233, 242, 116, 233, 170, 14, 24, 75}
+	if !bytes.Equal(key, expected) {
+		t.Errorf("expected %x, got %x", expected, key)
+	}
+	if _, err := PBES2DeriveKey(crypto.SHA256, []byte("password"), "PBES2-HS256+A128KW", sequence(4), 1000, 16); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if _, err := PBES2DeriveKey(crypto.SHA256, nil, "PBES2-HS256+A128KW", sequence(16), 1000, 16); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
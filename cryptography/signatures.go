@@ -2,12 +2,17 @@ package cryptography
 
 import (
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/sha512"
 	"fmt"
 	"hash"
+	"math/big"
 	"os"
 )
 
@@ -24,6 +29,7 @@ const (
 	AlgorithmPS256 = "PS256"
 	AlgorithmPS384 = "PS384"
 	AlgorithmPS512 = "PS512"
+	AlgorithmEdDSA = "EdDSA"
 	AlgorithmNone  = "none"
 )
 
@@ -133,3 +139,148 @@ func RSAVerify(algorithm string, key interface{}, jwsSigningInput string, signat
 	}
 	return false, fmt.Errorf("key must be a *rsa.PublicKey")
 }
+
+// ecdsaParameters returns the hash, curve and fixed coordinate size bound to an ECDSA algorithm per RFC 7518 §3.4.
+func ecdsaParameters(algorithm string) (crypto.Hash, elliptic.Curve, int, error) {
+	switch algorithm {
+	case AlgorithmES256:
+		return crypto.SHA256, elliptic.P256(), 32, nil
+	case AlgorithmES384:
+		return crypto.SHA384, elliptic.P384(), 48, nil
+	case AlgorithmES512:
+		return crypto.SHA512, elliptic.P521(), 66, nil
+	default:
+		return 0, nil, 0, fmt.Errorf("unsupported algorithm")
+	}
+}
+
+// ECDSASign signs a JWT using ECDSA algorithm and the provided private key, returning the R||S encoded signature bytes.
+func ECDSASign(algorithm string, key interface{}, jwsSigningInput string) ([]byte, error) {
+	ecdsaPrivateKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key must be a *ecdsa.PrivateKey")
+	}
+	h, curve, size, err := ecdsaParameters(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if ecdsaPrivateKey.Curve != curve {
+		return nil, fmt.Errorf("key curve does not match algorithm %s", algorithm)
+	}
+	i := h.New()
+	i.Write([]byte(jwsSigningInput))
+	r, s, err := ecdsa.Sign(rand.Reader, ecdsaPrivateKey, i.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	s.FillBytes(signature[size:])
+	return signature, nil
+}
+
+// ECDSAVerify verifies a JWT R||S encoded signature using ECDSA algorithm and the provided public key, returning a boolean indicating if the signature is valid.
+func ECDSAVerify(algorithm string, key interface{}, jwsSigningInput string, signature []byte) (bool, error) {
+	ecdsaPublicKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return false, fmt.Errorf("key must be a *ecdsa.PublicKey")
+	}
+	h, curve, size, err := ecdsaParameters(algorithm)
+	if err != nil {
+		return false, err
+	}
+	if ecdsaPublicKey.Curve != curve {
+		return false, fmt.Errorf("key curve does not match algorithm %s", algorithm)
+	}
+	if len(signature) != 2*size {
+		return false, nil
+	}
+	i := h.New()
+	i.Write([]byte(jwsSigningInput))
+	r := new(big.Int).SetBytes(signature[:size])
+	s := new(big.Int).SetBytes(signature[size:])
+	return ecdsa.Verify(ecdsaPublicKey, i.Sum(nil), r, s), nil
+}
+
+// rsaMinimumKeySize is the smallest modulus accepted for RSASSA-PSS and RSA key management per RFC 7518 §3.5, §4.2 and §4.3.
+const rsaMinimumKeySize = 2048
+
+// rsaPSSHash returns the hash bound to a RSASSA-PSS algorithm.
+func rsaPSSHash(algorithm string) (crypto.Hash, error) {
+	switch algorithm {
+	case AlgorithmPS256:
+		return crypto.SHA256, nil
+	case AlgorithmPS384:
+		return crypto.SHA384, nil
+	case AlgorithmPS512:
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported algorithm")
+	}
+}
+
+// RSAPSSSign signs a JWT using RSASSA-PSS algorithm and the provided private key, returning the signature bytes.
+func RSAPSSSign(algorithm string, key interface{}, jwsSigningInput string) ([]byte, error) {
+	rsaPrivateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key must be a *rsa.PrivateKey")
+	}
+	if rsaPrivateKey.N.BitLen() < rsaMinimumKeySize {
+		return nil, fmt.Errorf("key size must be at least %d bits", rsaMinimumKeySize)
+	}
+	h, err := rsaPSSHash(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	i := h.New()
+	i.Write([]byte(jwsSigningInput))
+	return rsa.SignPSS(rand.Reader, rsaPrivateKey, h, i.Sum(nil), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+}
+
+// RSAPSSVerify verifies a JWT signature using RSASSA-PSS algorithm and the provided public key, returning a boolean indicating if the signature is valid.
+func RSAPSSVerify(algorithm string, key interface{}, jwsSigningInput string, signature []byte) (bool, error) {
+	rsaPublicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return false, fmt.Errorf("key must be a *rsa.PublicKey")
+	}
+	if rsaPublicKey.N.BitLen() < rsaMinimumKeySize {
+		return false, fmt.Errorf("key size must be at least %d bits", rsaMinimumKeySize)
+	}
+	h, err := rsaPSSHash(algorithm)
+	if err != nil {
+		return false, err
+	}
+	i := h.New()
+	i.Write([]byte(jwsSigningInput))
+	return rsa.VerifyPSS(rsaPublicKey, h, i.Sum(nil), signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil, nil
+}
+
+// EdDSASign signs a JWT using EdDSA algorithm and the provided Ed25519 private key, returning the signature bytes.
+func EdDSASign(algorithm string, key interface{}, jwsSigningInput string) ([]byte, error) {
+	if algorithm != AlgorithmEdDSA {
+		return nil, fmt.Errorf("unsupported algorithm")
+	}
+	ed25519PrivateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key must be a ed25519.PrivateKey")
+	}
+	if len(ed25519PrivateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 private key size")
+	}
+	return ed25519.Sign(ed25519PrivateKey, []byte(jwsSigningInput)), nil
+}
+
+// EdDSAVerify verifies a JWT signature using EdDSA algorithm and the provided Ed25519 public key, returning a boolean indicating if the signature is valid.
+func EdDSAVerify(algorithm string, key interface{}, jwsSigningInput string, signature []byte) (bool, error) {
+	if algorithm != AlgorithmEdDSA {
+		return false, fmt.Errorf("unsupported algorithm")
+	}
+	ed25519PublicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return false, fmt.Errorf("key must be a ed25519.PublicKey")
+	}
+	if len(ed25519PublicKey) != ed25519.PublicKeySize {
+		return false, fmt.Errorf("invalid Ed25519 public key size")
+	}
+	return ed25519.Verify(ed25519PublicKey, []byte(jwsSigningInput), signature), nil
+}
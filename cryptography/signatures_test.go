@@ -1,10 +1,16 @@
 package cryptography
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/pem"
+	"math/big"
 	"testing"
 )
 
@@ -100,3 +106,144 @@ func TestRSA(t *testing.T) {
 		}
 	}
 }
+
+func TestECDSA(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		curve     elliptic.Curve
+		size      int
+	}{
+		{algorithm: "ES256", curve: elliptic.P256(), size: 64},
+		{algorithm: "ES384", curve: elliptic.P384(), size: 96},
+		{algorithm: "ES512", curve: elliptic.P521(), size: 132},
+	}
+	for _, test := range tests {
+		private, err := ecdsa.GenerateKey(test.curve, rand.Reader)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		signature, err := ECDSASign(test.algorithm, private, "1234")
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(signature) != test.size {
+			t.Errorf("expected signature of %d bytes, got %d", test.size, len(signature))
+		}
+		verify, err := ECDSAVerify(test.algorithm, &private.PublicKey, "1234", signature)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !verify {
+			t.Errorf("expected true, got false")
+		}
+		verify, _ = ECDSAVerify(test.algorithm, &private.PublicKey, "12345", signature)
+		if verify {
+			t.Errorf("expected false, got true")
+		}
+	}
+}
+
+func TestECDSARFC7515(t *testing.T) {
+	// RFC 7515 Appendix A.3
+	decode := func(s string) []byte {
+		b, _ := base64.RawURLEncoding.DecodeString(s)
+		return b
+	}
+	public := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(decode("f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU")),
+		Y:     new(big.Int).SetBytes(decode("x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0")),
+	}
+	input := "eyJhbGciOiJFUzI1NiJ9.eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ"
+	signature := decode("DtEhU3ljbEg8L38VWAfUAqOyKAM6-Xx-F4GawxaepmXFCgfTjDxw5djxLa8ISlSApmWQxfKTUJqPP3-Kg6NU1Q")
+	verify, err := ECDSAVerify("ES256", public, input, signature)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !verify {
+		t.Errorf("expected true, got false")
+	}
+}
+
+func TestECDSACurveMismatch(t *testing.T) {
+	private, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := ECDSASign("ES384", private, "1234"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	signature, _ := ECDSASign("ES256", private, "1234")
+	if _, err := ECDSAVerify("ES512", &private.PublicKey, "1234", signature); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if _, err := ECDSASign("ES256", []byte("key"), "1234"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestRSAPSS(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, algorithm := range []string{"PS256", "PS384", "PS512"} {
+		signature, err := RSAPSSSign(algorithm, private, "1234")
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		verify, err := RSAPSSVerify(algorithm, &private.PublicKey, "1234", signature)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !verify {
+			t.Errorf("expected true, got false")
+		}
+		verify, _ = RSAPSSVerify(algorithm, &private.PublicKey, "12345", signature)
+		if verify {
+			t.Errorf("expected false, got true")
+		}
+	}
+}
+
+func TestRSAPSSMinimumKeySize(t *testing.T) {
+	block, _ := pem.Decode([]byte(RSA_PRIVATE_KEY))
+	private, _ := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if _, err := RSAPSSSign("PS256", private, "1234"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if _, err := RSAPSSVerify("PS256", &private.PublicKey, "1234", []byte("signature")); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestEdDSA(t *testing.T) {
+	// RFC 8037 Appendix A.4
+	decode := func(s string) []byte {
+		b, _ := base64.RawURLEncoding.DecodeString(s)
+		return b
+	}
+	private := ed25519.NewKeyFromSeed(decode("nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"))
+	public := ed25519.PublicKey(decode("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"))
+	input := "eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc"
+	expected := "hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg"
+
+	signature, err := EdDSASign("EdDSA", private, input)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if b64 := base64.RawURLEncoding.EncodeToString(signature); b64 != expected {
+		t.Errorf("expected %s, got %s", expected, b64)
+	}
+	verify, err := EdDSAVerify("EdDSA", public, input, signature)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !verify {
+		t.Errorf("expected true, got false")
+	}
+	verify, _ = EdDSAVerify("EdDSA", public, input+".", signature)
+	if verify {
+		t.Errorf("expected false, got true")
+	}
+	if _, err := EdDSASign("EdDSA", []byte("key"), input); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
@@ -1,7 +1,11 @@
 // Reference: https://datatracker.ietf.org/doc/html/rfc7518
 package hermes
 
-import "github.com/prulloac/hermes-jwt/cryptography"
+import (
+	"fmt"
+
+	"github.com/prulloac/hermes-jwt/cryptography"
+)
 
 func (j JWT) Algorithm() string {
 	return j.header.Algorithm()
@@ -25,6 +29,7 @@ func IsJWS(s string) bool {
 		cryptography.AlgorithmPS256,
 		cryptography.AlgorithmPS384,
 		cryptography.AlgorithmPS512,
+		cryptography.AlgorithmEdDSA,
 		cryptography.AlgorithmNone:
 		return true
 	default:
@@ -41,9 +46,9 @@ const (
 	AlgorithmA256KW             = "A256KW"
 	AlgorithmDir                = "dir"
 	AlgorithmECDH_ES            = "ECDH-ES"
-	AlgorithmECDH_ES_A128KW     = "ECDH-EA+A128KW"
-	AlgorithmECDH_ES_A192KW     = "ECDH-EA+A192KW"
-	AlgorithmECDH_ES_A256KW     = "ECDH-EA+A256KW"
+	AlgorithmECDH_ES_A128KW     = "ECDH-ES+A128KW"
+	AlgorithmECDH_ES_A192KW     = "ECDH-ES+A192KW"
+	AlgorithmECDH_ES_A256KW     = "ECDH-ES+A256KW"
 	AlgorithmA128GCMKW          = "A128GCMKW"
 	AlgorithmA192GCMKW          = "A192GCMKW"
 	AlgorithmA256GCMKW          = "A256GCMKW"
@@ -80,3 +85,49 @@ func IsJWE(s string) bool {
 		return false
 	}
 }
+
+// keyWrapKeySize returns the key encryption key length in bytes required by an AES key wrapping "alg" algorithm.
+func keyWrapKeySize(alg string) (int, error) {
+	switch alg {
+	case AlgorithmA128KW, AlgorithmA128GCMKW, AlgorithmECDH_ES_A128KW, AlgorithmPBES2_HS256_A128KW:
+		return 16, nil
+	case AlgorithmA192KW, AlgorithmA192GCMKW, AlgorithmECDH_ES_A192KW, AlgorithmPBES2_HS384_A192KW:
+		return 24, nil
+	case AlgorithmA256KW, AlgorithmA256GCMKW, AlgorithmECDH_ES_A256KW, AlgorithmPBES2_HS512_A256KW:
+		return 32, nil
+	default:
+		return 0, fmt.Errorf("unsupported algorithm")
+	}
+}
+
+const (
+	EncryptionA128CBC_HS256 = "A128CBC-HS256"
+	EncryptionA192CBC_HS384 = "A192CBC-HS384"
+	EncryptionA256CBC_HS512 = "A256CBC-HS512"
+	EncryptionA128GCM       = "A128GCM"
+	EncryptionA192GCM       = "A192GCM"
+	EncryptionA256GCM       = "A256GCM"
+)
+
+func IsJWEEncryption(s string) bool {
+	_, err := contentEncryptionKeySize(s)
+	return err == nil
+}
+
+// contentEncryptionKeySize returns the CEK length in bytes required by an "enc" algorithm.
+func contentEncryptionKeySize(enc string) (int, error) {
+	switch enc {
+	case EncryptionA128GCM:
+		return 16, nil
+	case EncryptionA192GCM:
+		return 24, nil
+	case EncryptionA128CBC_HS256, EncryptionA256GCM:
+		return 32, nil
+	case EncryptionA192CBC_HS384:
+		return 48, nil
+	case EncryptionA256CBC_HS512:
+		return 64, nil
+	default:
+		return 0, fmt.Errorf("unsupported content encryption algorithm")
+	}
+}
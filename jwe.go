@@ -1,10 +1,667 @@
 // Reference: https://datatracker.ietf.org/doc/html/rfc7516
 package hermes
 
-func (j JWT) Encrypt(key interface{}) (string, error) {
-	return "", nil
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/prulloac/hermes-jwt/cryptography"
+)
+
+const (
+	EncryptionAlgorithmHeader  = "enc"
+	CompressionAlgorithmHeader = "zip"
+)
+
+const CompressionDeflate = "DEF"
+
+// JWEOption configures JWT.Encrypt and JWT.Decrypt.
+type JWEOption func(*jweOptions)
+
+type jweOptions struct {
+	allowRSA1_5       bool
+	allowPBES2        bool
+	pbes2MinCount     int
+	pbes2MaxCount     int
+	pbes2DefaultCount int
+	maxDecompressed   int64
+	err               error
+}
+
+const (
+	defaultPBES2MinCount = 1000
+	defaultPBES2MaxCount = 200000
+	defaultPBES2Count    = 100000
+	defaultPBES2SaltSize = 16
+
+	defaultMaxDecompressedSize = 1 << 20
+)
+
+func newJWEOptions(opts []JWEOption) jweOptions {
+	o := jweOptions{
+		pbes2MinCount:     defaultPBES2MinCount,
+		pbes2MaxCount:     defaultPBES2MaxCount,
+		pbes2DefaultCount: defaultPBES2Count,
+		maxDecompressed:   defaultMaxDecompressedSize,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// AllowRSA1_5 enables the RSA1_5 key management algorithm, which is rejected by
+// default because RSAES-PKCS1-v1_5 is exposed to padding oracle attacks.
+func AllowRSA1_5() JWEOption {
+	return func(o *jweOptions) {
+		o.allowRSA1_5 = true
+	}
+}
+
+// AllowPBES2 lets Decrypt use a []byte key as a PBES2 password. PBES2 is rejected by default
+// because the "p2c" header lets the sender of a token choose how much key derivation work it costs,
+// even when the key was only meant for "dir" or AES key wrapping.
+func AllowPBES2() JWEOption {
+	return func(o *jweOptions) {
+		o.allowPBES2 = true
+	}
+}
+
+// WithPBES2IterationLimits sets the lowest and highest PBES2 "p2c" iteration counts accepted, bounding the
+// work an attacker supplied header can demand from Decrypt. Encrypt uses the floor as its minimum count.
+// The limits default to 1000 and 200000; a range whose floor exceeds its ceiling makes Encrypt and
+// Decrypt fail.
+func WithPBES2IterationLimits(min int, max int) JWEOption {
+	return func(o *jweOptions) {
+		if min > max {
+			o.err = fmt.Errorf("invalid PBES2 iteration limits: %d is greater than %d", min, max)
+			return
+		}
+		o.pbes2MinCount = min
+		o.pbes2MaxCount = max
+		if o.pbes2DefaultCount < min {
+			o.pbes2DefaultCount = min
+		}
+		if o.pbes2DefaultCount > max {
+			o.pbes2DefaultCount = max
+		}
+	}
+}
+
+// WithMaxDecompressedSize sets the largest plaintext, in bytes, that Decrypt inflates from a
+// "zip":"DEF" JWE, protecting against decompression bombs. It defaults to 1 MiB.
+func WithMaxDecompressedSize(n int64) JWEOption {
+	return func(o *jweOptions) {
+		o.maxDecompressed = n
+	}
+}
+
+// Encrypt encrypts the JWT claims set with the "alg" and "enc" of its header, returning the JWE compact serialization.
+func (j JWT) Encrypt(key interface{}, opts ...JWEOption) (string, error) {
+	plaintext, err := j.payload.toJSON()
+	if err != nil {
+		return "", err
+	}
+	return encryptJWE(j.header, plaintext, key, newJWEOptions(opts))
+}
+
+// Decrypt decrypts a JWE parsed with ParseJWE, returning the plaintext. When the
+// plaintext is a JSON object it also becomes the claims set of the JWT.
+func (j *JWT) Decrypt(key interface{}, opts ...JWEOption) (string, error) {
+	if !j.IsJWE() || j.compact == "" {
+		j.state = InvalidJWT
+		return "", fmt.Errorf("JWT is not a valid JWE")
+	}
+	protected := strings.SplitN(j.compact, ".", 2)[0]
+	enc := j.header.EncryptionAlgorithm()
+	o := newJWEOptions(opts)
+	if o.err != nil {
+		return "", o.err
+	}
+	cek, err := decryptKey(j.header, key, j.encryptedKey, enc, o)
+	if err != nil {
+		j.state = EncryptionInvalid
+		return "", err
+	}
+	plaintext, err := decryptContent(enc, cek, j.iv, j.ciphertext, j.tag, []byte(protected))
+	if err != nil {
+		j.state = EncryptionInvalid
+		return "", err
+	}
+	if j.header.CompressionAlgorithm() == CompressionDeflate {
+		if plaintext, err = decompress(plaintext, o.maxDecompressed); err != nil {
+			j.state = EncryptionInvalid
+			return "", err
+		}
+	}
+	j.state = EncryptionVerified
+	if claims, err := decodeClaims(plaintext, false); err == nil {
+		j.payload = claims
+	}
+	return string(plaintext), nil
+}
+
+// encryptJWE produces the five-part compact serialization of plaintext, using the
+// protected header as additional authenticated data.
+func encryptJWE(header JoseHeader, plaintext []byte, key interface{}, o jweOptions) (string, error) {
+	if o.err != nil {
+		return "", o.err
+	}
+	h := make(JoseHeader, len(header))
+	for k, v := range header {
+		h[k] = v
+	}
+	if !IsJWE(h.Algorithm()) {
+		return "", fmt.Errorf("not a JWE")
+	}
+	enc := h.EncryptionAlgorithm()
+	if !IsJWEEncryption(enc) {
+		return "", fmt.Errorf("unsupported content encryption algorithm")
+	}
+	switch h.CompressionAlgorithm() {
+	case "":
+	case CompressionDeflate:
+		compressed, err := compress(plaintext)
+		if err != nil {
+			return "", err
+		}
+		plaintext = compressed
+	default:
+		return "", fmt.Errorf("unsupported compression algorithm")
+	}
+	cek, encryptedKey, err := encryptKey(h, key, enc, o)
+	if err != nil {
+		return "", err
+	}
+	protected := h.ToBase64URL()
+	iv, ciphertext, tag, err := encryptContent(enc, cek, plaintext, []byte(protected))
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{
+		protected,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// compress applies DEFLATE (RFC 1951) to the plaintext.
+func compress(plaintext []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress inflates DEFLATE compressed data, failing when the output would exceed max bytes.
+func decompress(compressed []byte, max int64) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(compressed))
+	defer r.Close()
+	plaintext, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed plaintext: %w", err)
+	}
+	if int64(len(plaintext)) > max {
+		return nil, fmt.Errorf("decompressed plaintext exceeds %d bytes", max)
+	}
+	return plaintext, nil
+}
+
+// encryptKey determines the content encryption key for the "alg" of the header and returns it with its JWE Encrypted Key.
+// Algorithms that define additional header parameters set them on the header.
+func encryptKey(header JoseHeader, key interface{}, enc string, o jweOptions) ([]byte, []byte, error) {
+	alg := header.Algorithm()
+	if alg == AlgorithmRSA1_5 && !o.allowRSA1_5 {
+		return nil, nil, fmt.Errorf("algorithm %s is not allowed", alg)
+	}
+	switch alg {
+	case AlgorithmDir:
+		cek, err := directKey(key, enc)
+		return cek, nil, err
+	case AlgorithmECDH_ES:
+		size, err := contentEncryptionKeySize(enc)
+		if err != nil {
+			return nil, nil, err
+		}
+		cek, err := ecdhesSenderKey(header, key, enc, size)
+		return cek, nil, err
+	}
+	cek, err := generateContentEncryptionKey(enc)
+	if err != nil {
+		return nil, nil, err
+	}
+	var encryptedKey []byte
+	switch alg {
+	case AlgorithmRSA1_5:
+		encryptedKey, err = cryptography.RSAPKCS1v15Encrypt(key, cek)
+	case AlgorithmRSA_OAEP:
+		encryptedKey, err = cryptography.RSAOAEPEncrypt(crypto.SHA1, key, cek)
+	case AlgorithmRSA_OAEP_256:
+		encryptedKey, err = cryptography.RSAOAEPEncrypt(crypto.SHA256, key, cek)
+	case AlgorithmA128KW, AlgorithmA192KW, AlgorithmA256KW:
+		var kek []byte
+		if kek, err = keyEncryptionKey(alg, key); err == nil {
+			encryptedKey, err = cryptography.AESKeyWrap(kek, cek)
+		}
+	case AlgorithmA128GCMKW, AlgorithmA192GCMKW, AlgorithmA256GCMKW:
+		var kek, iv, tag []byte
+		if kek, err = keyEncryptionKey(alg, key); err == nil {
+			iv, encryptedKey, tag, err = cryptography.AESGCMKeyWrap(kek, cek)
+			header["iv"] = base64.RawURLEncoding.EncodeToString(iv)
+			header["tag"] = base64.RawURLEncoding.EncodeToString(tag)
+		}
+	case AlgorithmECDH_ES_A128KW, AlgorithmECDH_ES_A192KW, AlgorithmECDH_ES_A256KW:
+		var kek []byte
+		size, _ := keyWrapKeySize(alg)
+		if kek, err = ecdhesSenderKey(header, key, alg, size); err == nil {
+			encryptedKey, err = cryptography.AESKeyWrap(kek, cek)
+		}
+	case AlgorithmPBES2_HS256_A128KW, AlgorithmPBES2_HS384_A192KW, AlgorithmPBES2_HS512_A256KW:
+		var kek []byte
+		if kek, err = pbes2SenderKey(header, key, o); err == nil {
+			encryptedKey, err = cryptography.AESKeyWrap(kek, cek)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported algorithm")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, encryptedKey, nil
+}
+
+// decryptKey recovers the content encryption key from the JWE Encrypted Key for the "alg" of the header.
+func decryptKey(header JoseHeader, key interface{}, encryptedKey []byte, enc string, o jweOptions) ([]byte, error) {
+	switch alg := header.Algorithm(); alg {
+	case AlgorithmRSA1_5:
+		if !o.allowRSA1_5 {
+			return nil, fmt.Errorf("algorithm %s is not allowed", alg)
+		}
+		size, err := contentEncryptionKeySize(enc)
+		if err != nil {
+			return nil, err
+		}
+		return cryptography.RSAPKCS1v15Decrypt(key, encryptedKey, size)
+	case AlgorithmRSA_OAEP:
+		return cryptography.RSAOAEPDecrypt(crypto.SHA1, key, encryptedKey)
+	case AlgorithmRSA_OAEP_256:
+		return cryptography.RSAOAEPDecrypt(crypto.SHA256, key, encryptedKey)
+	case AlgorithmA128KW, AlgorithmA192KW, AlgorithmA256KW:
+		kek, err := keyEncryptionKey(alg, key)
+		if err != nil {
+			return nil, err
+		}
+		return cryptography.AESKeyUnwrap(kek, encryptedKey)
+	case AlgorithmA128GCMKW, AlgorithmA192GCMKW, AlgorithmA256GCMKW:
+		kek, err := keyEncryptionKey(alg, key)
+		if err != nil {
+			return nil, err
+		}
+		iv, err := header.bytesParameter("iv")
+		if err != nil {
+			return nil, err
+		}
+		tag, err := header.bytesParameter("tag")
+		if err != nil {
+			return nil, err
+		}
+		return cryptography.AESGCMKeyUnwrap(kek, iv, encryptedKey, tag)
+	case AlgorithmDir:
+		if len(encryptedKey) != 0 {
+			return nil, fmt.Errorf("encrypted key must be empty for %s", alg)
+		}
+		return directKey(key, enc)
+	case AlgorithmECDH_ES:
+		if len(encryptedKey) != 0 {
+			return nil, fmt.Errorf("encrypted key must be empty for %s", alg)
+		}
+		size, err := contentEncryptionKeySize(enc)
+		if err != nil {
+			return nil, err
+		}
+		return ecdhesRecipientKey(header, key, enc, size)
+	case AlgorithmECDH_ES_A128KW, AlgorithmECDH_ES_A192KW, AlgorithmECDH_ES_A256KW:
+		size, _ := keyWrapKeySize(alg)
+		kek, err := ecdhesRecipientKey(header, key, alg, size)
+		if err != nil {
+			return nil, err
+		}
+		return cryptography.AESKeyUnwrap(kek, encryptedKey)
+	case AlgorithmPBES2_HS256_A128KW, AlgorithmPBES2_HS384_A192KW, AlgorithmPBES2_HS512_A256KW:
+		if !o.allowPBES2 {
+			return nil, fmt.Errorf("algorithm %s is not allowed", alg)
+		}
+		saltInput, err := header.bytesParameter("p2s")
+		if err != nil {
+			return nil, err
+		}
+		count, err := pbes2Count(header, o)
+		if err != nil {
+			return nil, err
+		}
+		kek, err := pbes2DeriveKey(alg, key, saltInput, count)
+		if err != nil {
+			return nil, err
+		}
+		return cryptography.AESKeyUnwrap(kek, encryptedKey)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm")
+	}
+}
+
+// encryptContent encrypts the plaintext with the content encryption key, returning the IV, ciphertext and authentication tag.
+func encryptContent(enc string, cek []byte, plaintext []byte, aad []byte) ([]byte, []byte, []byte, error) {
+	if err := checkContentEncryptionKey(enc, cek); err != nil {
+		return nil, nil, nil, err
+	}
+	switch enc {
+	case EncryptionA128CBC_HS256, EncryptionA192CBC_HS384, EncryptionA256CBC_HS512:
+		return cryptography.AESCBCHMACEncrypt(cek, plaintext, aad)
+	case EncryptionA128GCM, EncryptionA192GCM, EncryptionA256GCM:
+		return cryptography.AESGCMEncrypt(cek, plaintext, aad)
+	default:
+		return nil, nil, nil, fmt.Errorf("unsupported content encryption algorithm")
+	}
+}
+
+// decryptContent authenticates and decrypts the ciphertext with the content encryption key.
+func decryptContent(enc string, cek []byte, iv []byte, ciphertext []byte, tag []byte, aad []byte) ([]byte, error) {
+	if err := checkContentEncryptionKey(enc, cek); err != nil {
+		return nil, err
+	}
+	switch enc {
+	case EncryptionA128CBC_HS256, EncryptionA192CBC_HS384, EncryptionA256CBC_HS512:
+		return cryptography.AESCBCHMACDecrypt(cek, iv, ciphertext, tag, aad)
+	case EncryptionA128GCM, EncryptionA192GCM, EncryptionA256GCM:
+		return cryptography.AESGCMDecrypt(cek, iv, ciphertext, tag, aad)
+	default:
+		return nil, fmt.Errorf("unsupported content encryption algorithm")
+	}
+}
+
+// checkContentEncryptionKey ensures the CEK has the exact length required by the "enc" algorithm.
+func checkContentEncryptionKey(enc string, cek []byte) error {
+	size, err := contentEncryptionKeySize(enc)
+	if err != nil {
+		return err
+	}
+	if len(cek) != size {
+		return fmt.Errorf("content encryption key must be %d bytes for %s", size, enc)
+	}
+	return nil
+}
+
+// ecdhesSenderKey performs ECDH-ES key agreement with an ephemeral key against the recipient public key,
+// recording the ephemeral public key as the "epk" header parameter, and derives size bytes with the Concat KDF.
+func ecdhesSenderKey(header JoseHeader, key interface{}, algorithmID string, size int) ([]byte, error) {
+	ephemeral, z, err := cryptography.ECDHESGenerate(key)
+	if err != nil {
+		return nil, err
+	}
+	epk, err := NewJWK(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	header["epk"] = epk
+	return ecdhesDeriveKey(header, z, algorithmID, size)
+}
+
+// ecdhesRecipientKey performs ECDH-ES key agreement with the recipient private key against the "epk" header
+// parameter and derives size bytes with the Concat KDF.
+func ecdhesRecipientKey(header JoseHeader, key interface{}, algorithmID string, size int) ([]byte, error) {
+	epk, err := header.jwkParameter("epk")
+	if err != nil {
+		return nil, err
+	}
+	z, err := cryptography.ECDHESAgree(key, epk.Key)
+	if err != nil {
+		return nil, err
+	}
+	return ecdhesDeriveKey(header, z, algorithmID, size)
+}
+
+func ecdhesDeriveKey(header JoseHeader, z []byte, algorithmID string, size int) ([]byte, error) {
+	apu, err := header.optionalBytesParameter("apu")
+	if err != nil {
+		return nil, err
+	}
+	apv, err := header.optionalBytesParameter("apv")
+	if err != nil {
+		return nil, err
+	}
+	return cryptography.ConcatKDF(crypto.SHA256, z, []byte(algorithmID), apu, apv, size), nil
+}
+
+// pbes2SenderKey derives the PBES2 key encryption key, generating the "p2s" salt input and
+// defaulting the "p2c" iteration count when the header does not set them.
+func pbes2SenderKey(header JoseHeader, key interface{}, o jweOptions) ([]byte, error) {
+	if _, ok := header["p2s"]; !ok {
+		saltInput := make([]byte, defaultPBES2SaltSize)
+		if _, err := rand.Read(saltInput); err != nil {
+			return nil, err
+		}
+		header["p2s"] = base64.RawURLEncoding.EncodeToString(saltInput)
+	}
+	if _, ok := header["p2c"]; !ok {
+		header["p2c"] = o.pbes2DefaultCount
+	}
+	saltInput, err := header.bytesParameter("p2s")
+	if err != nil {
+		return nil, err
+	}
+	count, err := pbes2Count(header, o)
+	if err != nil {
+		return nil, err
+	}
+	return pbes2DeriveKey(header.Algorithm(), key, saltInput, count)
+}
+
+// pbes2Count reads the "p2c" header parameter, enforcing the configured iteration limits.
+func pbes2Count(header JoseHeader, o jweOptions) (int, error) {
+	count, err := header.intParameter("p2c")
+	if err != nil {
+		return 0, err
+	}
+	if count < o.pbes2MinCount || count > o.pbes2MaxCount {
+		return 0, fmt.Errorf("p2c must be between %d and %d", o.pbes2MinCount, o.pbes2MaxCount)
+	}
+	return count, nil
+}
+
+func pbes2DeriveKey(alg string, key interface{}, saltInput []byte, count int) ([]byte, error) {
+	password, ok := key.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("key must be a byte slice")
+	}
+	var hash crypto.Hash
+	switch alg {
+	case AlgorithmPBES2_HS256_A128KW:
+		hash = crypto.SHA256
+	case AlgorithmPBES2_HS384_A192KW:
+		hash = crypto.SHA384
+	case AlgorithmPBES2_HS512_A256KW:
+		hash = crypto.SHA512
+	default:
+		return nil, fmt.Errorf("unsupported algorithm")
+	}
+	size, err := keyWrapKeySize(alg)
+	if err != nil {
+		return nil, err
+	}
+	return cryptography.PBES2DeriveKey(hash, password, alg, saltInput, count, size)
+}
+
+// directKey returns the shared symmetric key used as the CEK by "dir", checking its length against the "enc" algorithm.
+func directKey(key interface{}, enc string) ([]byte, error) {
+	cek, ok := key.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("key must be a byte slice")
+	}
+	if err := checkContentEncryptionKey(enc, cek); err != nil {
+		return nil, err
+	}
+	return cek, nil
+}
+
+// keyEncryptionKey returns the symmetric key, checking it has the length required by the key wrapping algorithm.
+func keyEncryptionKey(alg string, key interface{}) ([]byte, error) {
+	kek, ok := key.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("key must be a byte slice")
+	}
+	size, err := keyWrapKeySize(alg)
+	if err != nil {
+		return nil, err
+	}
+	if len(kek) != size {
+		return nil, fmt.Errorf("key must be %d bytes for %s", size, alg)
+	}
+	return kek, nil
+}
+
+// generateContentEncryptionKey returns a random CEK of the length required by the "enc" algorithm.
+func generateContentEncryptionKey(enc string) ([]byte, error) {
+	size, err := contentEncryptionKeySize(enc)
+	if err != nil {
+		return nil, err
+	}
+	cek := make([]byte, size)
+	if _, err := rand.Read(cek); err != nil {
+		return nil, err
+	}
+	return cek, nil
+}
+
+func ParseJWE(jwe string) (JWT, error) {
+	if jwe == "" {
+		return JWT{}, fmt.Errorf("empty JWT")
+	}
+	parts := strings.Split(jwe, ".")
+	if len(parts) != 5 {
+		return JWT{}, fmt.Errorf("invalid JWE")
+	}
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return JWT{}, err
+	}
+	var h JoseHeader = make(map[string]interface{})
+	if err := json.Unmarshal(header, &h); err != nil {
+		return JWT{}, err
+	}
+	if !IsJWE(h.Algorithm()) {
+		return JWT{}, fmt.Errorf("not a JWE")
+	}
+	if !IsJWEEncryption(h.EncryptionAlgorithm()) {
+		return JWT{}, fmt.Errorf("unsupported content encryption algorithm")
+	}
+	if zip := h.CompressionAlgorithm(); zip != "" && zip != CompressionDeflate {
+		return JWT{}, fmt.Errorf("unsupported compression algorithm")
+	}
+	decoded := make([][]byte, 4)
+	for i, part := range parts[1:] {
+		if decoded[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return JWT{}, err
+		}
+	}
+	if len(decoded[1]) == 0 || len(decoded[3]) == 0 {
+		return JWT{}, fmt.Errorf("invalid JWE")
+	}
+	return JWT{
+		header:       h,
+		encryptedKey: decoded[0],
+		iv:           decoded[1],
+		ciphertext:   decoded[2],
+		tag:          decoded[3],
+		compact:      jwe,
+		state:        EncryptionUnverified,
+	}, nil
+}
+
+func (j JoseHeader) EncryptionAlgorithm() string {
+	enc, _ := j.Parameter(EncryptionAlgorithmHeader).(string)
+	return enc
+}
+
+func (j JoseHeader) CompressionAlgorithm() string {
+	zip, _ := j.Parameter(CompressionAlgorithmHeader).(string)
+	return zip
+}
+
+// bytesParameter decodes a base64url encoded header parameter.
+func (j JoseHeader) bytesParameter(key string) ([]byte, error) {
+	value, ok := j.Parameter(key).(string)
+	if !ok || value == "" {
+		return nil, fmt.Errorf("missing %s header parameter", key)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s header parameter", key)
+	}
+	return b, nil
+}
+
+// optionalBytesParameter decodes a base64url encoded header parameter, returning nil when it is absent.
+func (j JoseHeader) optionalBytesParameter(key string) ([]byte, error) {
+	if _, ok := j[key]; !ok {
+		return nil, nil
+	}
+	return j.bytesParameter(key)
+}
+
+// jwkParameter parses a header parameter holding a public JWK, such as "epk".
+func (j JoseHeader) jwkParameter(key string) (JWK, error) {
+	value, ok := j[key]
+	if !ok {
+		return JWK{}, fmt.Errorf("missing %s header parameter", key)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return JWK{}, err
+	}
+	jwk, err := ParseJWK(b)
+	if err != nil {
+		return JWK{}, fmt.Errorf("invalid %s header parameter: %w", key, err)
+	}
+	if jwk.IsPrivate() {
+		return JWK{}, fmt.Errorf("invalid %s header parameter: must be a public key", key)
+	}
+	return jwk, nil
 }
 
-func (j JWT) Decrypt(key interface{}) (string, error) {
-	return "", nil
+// intParameter reads an integer header parameter, which is decoded from JSON as a float64.
+func (j JoseHeader) intParameter(key string) (int, error) {
+	switch value := j.Parameter(key).(type) {
+	case int:
+		return value, nil
+	case int64:
+		return int(value), nil
+	case float64:
+		if value == float64(int(value)) {
+			return int(value), nil
+		}
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return int(i), nil
+		}
+	case nil:
+		return 0, fmt.Errorf("missing %s header parameter", key)
+	}
+	return 0, fmt.Errorf("invalid %s header parameter", key)
 }
@@ -1 +1,682 @@
+// Reference: https://datatracker.ietf.org/doc/html/rfc7517
 package hermes
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+const JWK_MEDIA_TYPE = "application/jwk+json"
+const JWK_SET_MEDIA_TYPE = "application/jwk-set+json"
+
+const (
+	KeyTypeRSA = "RSA"
+	KeyTypeEC  = "EC"
+	KeyTypeOct = "oct"
+	KeyTypeOKP = "OKP"
+)
+
+const (
+	CurveP256    = "P-256"
+	CurveP384    = "P-384"
+	CurveP521    = "P-521"
+	CurveEd25519 = "Ed25519"
+	CurveX25519  = "X25519"
+)
+
+const (
+	KeyUseSignature  = "sig"
+	KeyUseEncryption = "enc"
+)
+
+const (
+	KeyOperationSign       = "sign"
+	KeyOperationVerify     = "verify"
+	KeyOperationEncrypt    = "encrypt"
+	KeyOperationDecrypt    = "decrypt"
+	KeyOperationWrapKey    = "wrapKey"
+	KeyOperationUnwrapKey  = "unwrapKey"
+	KeyOperationDeriveKey  = "deriveKey"
+	KeyOperationDeriveBits = "deriveBits"
+)
+
+// JWK is a JSON Web Key. Key holds the key material as the values consumed by the
+// cryptography package: *rsa.PublicKey, *rsa.PrivateKey, *ecdsa.PublicKey,
+// *ecdsa.PrivateKey, ed25519.PublicKey, ed25519.PrivateKey, X25519 *ecdh.PublicKey and
+// *ecdh.PrivateKey or []byte for symmetric keys.
+type JWK struct {
+	Key                             interface{}
+	KeyID                           string
+	Algorithm                       string
+	Use                             string
+	KeyOperations                   []string
+	X509URL                         string
+	X509CertificateChain            []*x509.Certificate
+	X509CertificateSHA1Thumbprint   []byte
+	X509CertificateSHA256Thumbprint []byte
+}
+
+// jwkJSON is the wire representation of a JWK, holding every member defined for the supported key types.
+type jwkJSON struct {
+	KeyType       string          `json:"kty"`
+	Use           string          `json:"use,omitempty"`
+	KeyOperations []string        `json:"key_ops,omitempty"`
+	Algorithm     string          `json:"alg,omitempty"`
+	KeyID         string          `json:"kid,omitempty"`
+	X5U           string          `json:"x5u,omitempty"`
+	X5C           []string        `json:"x5c,omitempty"`
+	X5T           string          `json:"x5t,omitempty"`
+	X5TS256       string          `json:"x5t#S256,omitempty"`
+	Crv           string          `json:"crv,omitempty"`
+	X             string          `json:"x,omitempty"`
+	Y             string          `json:"y,omitempty"`
+	N             string          `json:"n,omitempty"`
+	E             string          `json:"e,omitempty"`
+	D             string          `json:"d,omitempty"`
+	P             string          `json:"p,omitempty"`
+	Q             string          `json:"q,omitempty"`
+	DP            string          `json:"dp,omitempty"`
+	DQ            string          `json:"dq,omitempty"`
+	QI            string          `json:"qi,omitempty"`
+	Oth           json.RawMessage `json:"oth,omitempty"`
+	K             string          `json:"k,omitempty"`
+}
+
+// NewJWK wraps a supported crypto key in a JWK.
+func NewJWK(key interface{}) (JWK, error) {
+	k := JWK{Key: key}
+	if k.KeyType() == "" {
+		return JWK{}, fmt.Errorf("unsupported key type %T", key)
+	}
+	return k, nil
+}
+
+// ParseJWK parses and validates a single JSON Web Key.
+func ParseJWK(data []byte) (JWK, error) {
+	var k JWK
+	if err := json.Unmarshal(data, &k); err != nil {
+		return JWK{}, err
+	}
+	return k, nil
+}
+
+// KeyType returns the "kty" value matching the key material, or an empty string if it is not supported.
+func (k JWK) KeyType() string {
+	switch key := k.Key.(type) {
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		return KeyTypeRSA
+	case *ecdsa.PublicKey, *ecdsa.PrivateKey:
+		return KeyTypeEC
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		return KeyTypeOKP
+	case *ecdh.PublicKey:
+		if key.Curve() == ecdh.X25519() {
+			return KeyTypeOKP
+		}
+	case *ecdh.PrivateKey:
+		if key.Curve() == ecdh.X25519() {
+			return KeyTypeOKP
+		}
+	case []byte:
+		if len(key) > 0 {
+			return KeyTypeOct
+		}
+	}
+	return ""
+}
+
+// IsPrivate reports whether the key holds private or symmetric key material.
+func (k JWK) IsPrivate() bool {
+	switch k.Key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey, *ecdh.PrivateKey, []byte:
+		return true
+	default:
+		return false
+	}
+}
+
+// PublicKey returns the public key material, failing for symmetric keys.
+func (k JWK) PublicKey() (interface{}, error) {
+	switch key := k.Key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey, *ecdh.PublicKey:
+		return key, nil
+	case *rsa.PrivateKey:
+		return &key.PublicKey, nil
+	case *ecdsa.PrivateKey:
+		return &key.PublicKey, nil
+	case ed25519.PrivateKey:
+		return key.Public().(ed25519.PublicKey), nil
+	case *ecdh.PrivateKey:
+		return key.PublicKey(), nil
+	default:
+		return nil, fmt.Errorf("key has no public part")
+	}
+}
+
+// Public returns a copy of the JWK holding only the public key material.
+func (k JWK) Public() (JWK, error) {
+	public, err := k.PublicKey()
+	if err != nil {
+		return JWK{}, err
+	}
+	k.Key = public
+	return k, nil
+}
+
+// Validate checks the key material and the optional members of the JWK.
+func (k JWK) Validate() error {
+	if k.KeyType() == "" {
+		return fmt.Errorf("unsupported key type %T", k.Key)
+	}
+	seen := make(map[string]bool, len(k.KeyOperations))
+	for _, op := range k.KeyOperations {
+		if seen[op] {
+			return fmt.Errorf("duplicate key operation %s", op)
+		}
+		seen[op] = true
+	}
+	if len(k.X509CertificateSHA1Thumbprint) > 0 && len(k.X509CertificateSHA1Thumbprint) != sha1.Size {
+		return fmt.Errorf("invalid x5t length")
+	}
+	if len(k.X509CertificateSHA256Thumbprint) > 0 && len(k.X509CertificateSHA256Thumbprint) != sha256.Size {
+		return fmt.Errorf("invalid x5t#S256 length")
+	}
+	if len(k.X509CertificateChain) == 0 {
+		return nil
+	}
+	leaf := k.X509CertificateChain[0]
+	public, err := k.PublicKey()
+	if err != nil {
+		return fmt.Errorf("x5c is not allowed for %s keys", k.KeyType())
+	}
+	if !publicKeysEqual(public, leaf.PublicKey) {
+		return fmt.Errorf("x5c certificate does not match the key")
+	}
+	if len(k.X509CertificateSHA1Thumbprint) > 0 {
+		if sum := sha1.Sum(leaf.Raw); !bytes.Equal(sum[:], k.X509CertificateSHA1Thumbprint) {
+			return fmt.Errorf("x5t does not match the x5c certificate")
+		}
+	}
+	if len(k.X509CertificateSHA256Thumbprint) > 0 {
+		if sum := sha256.Sum256(leaf.Raw); !bytes.Equal(sum[:], k.X509CertificateSHA256Thumbprint) {
+			return fmt.Errorf("x5t#S256 does not match the x5c certificate")
+		}
+	}
+	return nil
+}
+
+// MarshalJSON serializes the JWK with the members required by its key type.
+func (k JWK) MarshalJSON() ([]byte, error) {
+	raw, err := k.toJSON()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(raw)
+}
+
+// toJSON builds the wire representation of the JWK.
+func (k JWK) toJSON() (jwkJSON, error) {
+	if err := k.Validate(); err != nil {
+		return jwkJSON{}, err
+	}
+	raw := jwkJSON{
+		KeyType:       k.KeyType(),
+		Use:           k.Use,
+		KeyOperations: k.KeyOperations,
+		Algorithm:     k.Algorithm,
+		KeyID:         k.KeyID,
+		X5U:           k.X509URL,
+		X5T:           encodeSegment(k.X509CertificateSHA1Thumbprint),
+		X5TS256:       encodeSegment(k.X509CertificateSHA256Thumbprint),
+	}
+	for _, cert := range k.X509CertificateChain {
+		raw.X5C = append(raw.X5C, base64.StdEncoding.EncodeToString(cert.Raw))
+	}
+	switch key := k.Key.(type) {
+	case *rsa.PublicKey:
+		marshalRSAPublicKey(&raw, key)
+	case *rsa.PrivateKey:
+		marshalRSAPublicKey(&raw, &key.PublicKey)
+		if len(key.Primes) != 2 {
+			return jwkJSON{}, fmt.Errorf("multi-prime RSA keys are not supported")
+		}
+		p, q := key.Primes[0], key.Primes[1]
+		one := big.NewInt(1)
+		raw.D = encodeSegment(key.D.Bytes())
+		raw.P = encodeSegment(p.Bytes())
+		raw.Q = encodeSegment(q.Bytes())
+		raw.DP = encodeSegment(new(big.Int).Mod(key.D, new(big.Int).Sub(p, one)).Bytes())
+		raw.DQ = encodeSegment(new(big.Int).Mod(key.D, new(big.Int).Sub(q, one)).Bytes())
+		raw.QI = encodeSegment(new(big.Int).ModInverse(q, p).Bytes())
+	case *ecdsa.PublicKey:
+		if err := marshalECPublicKey(&raw, key); err != nil {
+			return jwkJSON{}, err
+		}
+	case *ecdsa.PrivateKey:
+		if err := marshalECPublicKey(&raw, &key.PublicKey); err != nil {
+			return jwkJSON{}, err
+		}
+		raw.D = encodeSegment(key.D.FillBytes(make([]byte, curveSize(key.Curve))))
+	case ed25519.PublicKey:
+		raw.Crv = CurveEd25519
+		raw.X = encodeSegment(key)
+	case ed25519.PrivateKey:
+		raw.Crv = CurveEd25519
+		raw.X = encodeSegment(key.Public().(ed25519.PublicKey))
+		raw.D = encodeSegment(key.Seed())
+	case *ecdh.PublicKey:
+		raw.Crv = CurveX25519
+		raw.X = encodeSegment(key.Bytes())
+	case *ecdh.PrivateKey:
+		raw.Crv = CurveX25519
+		raw.X = encodeSegment(key.PublicKey().Bytes())
+		raw.D = encodeSegment(key.Bytes())
+	case []byte:
+		raw.K = encodeSegment(key)
+	}
+	return raw, nil
+}
+
+// UnmarshalJSON parses a JWK, enforcing the members required by its key type.
+func (k *JWK) UnmarshalJSON(data []byte) error {
+	var raw jwkJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	out := JWK{
+		KeyID:         raw.KeyID,
+		Algorithm:     raw.Algorithm,
+		Use:           raw.Use,
+		KeyOperations: raw.KeyOperations,
+		X509URL:       raw.X5U,
+	}
+	var err error
+	switch raw.KeyType {
+	case KeyTypeRSA:
+		out.Key, err = unmarshalRSAKey(raw)
+	case KeyTypeEC:
+		out.Key, err = unmarshalECKey(raw)
+	case KeyTypeOKP:
+		out.Key, err = unmarshalOKPKey(raw)
+	case KeyTypeOct:
+		out.Key, err = decodeRequiredMember(raw.K, "k")
+	case "":
+		return fmt.Errorf("missing required member kty")
+	default:
+		return &UnsupportedKeyTypeError{KeyType: raw.KeyType}
+	}
+	if err != nil {
+		return err
+	}
+	for _, c := range raw.X5C {
+		der, err := base64.StdEncoding.DecodeString(c)
+		if err != nil {
+			return fmt.Errorf("invalid x5c: %w", err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return fmt.Errorf("invalid x5c: %w", err)
+		}
+		out.X509CertificateChain = append(out.X509CertificateChain, cert)
+	}
+	if raw.X5T != "" {
+		if out.X509CertificateSHA1Thumbprint, err = base64.RawURLEncoding.Strict().DecodeString(raw.X5T); err != nil {
+			return fmt.Errorf("invalid x5t: %w", err)
+		}
+	}
+	if raw.X5TS256 != "" {
+		if out.X509CertificateSHA256Thumbprint, err = base64.RawURLEncoding.Strict().DecodeString(raw.X5TS256); err != nil {
+			return fmt.Errorf("invalid x5t#S256: %w", err)
+		}
+	}
+	if err := out.Validate(); err != nil {
+		return err
+	}
+	*k = out
+	return nil
+}
+
+// JWKSet is a JSON Web Key Set as defined in RFC 7517 §5.
+type JWKSet struct {
+	Keys    []JWK
+	skipped []error
+}
+
+// ParseJWKSet parses a JWK Set document. Members that cannot be used are
+// skipped rather than failing the whole set, and are reported by Skipped.
+func ParseJWKSet(data []byte) (JWKSet, error) {
+	var s JWKSet
+	if err := json.Unmarshal(data, &s); err != nil {
+		return JWKSet{}, err
+	}
+	return s, nil
+}
+
+// Skipped returns the errors for the members ignored while parsing the set.
+func (s JWKSet) Skipped() []error {
+	return s.skipped
+}
+
+// KeyByID returns the first key with the given "kid".
+func (s JWKSet) KeyByID(kid string) (JWK, error) {
+	for _, k := range s.Keys {
+		if k.KeyID == kid {
+			return k, nil
+		}
+	}
+	return JWK{}, fmt.Errorf("key %s not found", kid)
+}
+
+// KeysByType returns the keys with the given "kty".
+func (s JWKSet) KeysByType(kty string) JWKSet {
+	return s.filter(func(k JWK) bool { return k.KeyType() == kty })
+}
+
+// KeysByUse returns the keys with the given "use".
+func (s JWKSet) KeysByUse(use string) JWKSet {
+	return s.filter(func(k JWK) bool { return k.Use == use })
+}
+
+// KeysByAlgorithm returns the keys with the given "alg".
+func (s JWKSet) KeysByAlgorithm(alg string) JWKSet {
+	return s.filter(func(k JWK) bool { return k.Algorithm == alg })
+}
+
+func (s JWKSet) filter(match func(JWK) bool) JWKSet {
+	out := JWKSet{}
+	for _, k := range s.Keys {
+		if match(k) {
+			out.Keys = append(out.Keys, k)
+		}
+	}
+	return out
+}
+
+// Public returns a copy of the set holding only public keys, dropping symmetric keys.
+func (s JWKSet) Public() JWKSet {
+	out := JWKSet{}
+	for _, k := range s.Keys {
+		if public, err := k.Public(); err == nil {
+			out.Keys = append(out.Keys, public)
+		}
+	}
+	return out
+}
+
+// MarshalJSON serializes the set as a {"keys":[...]} document.
+func (s JWKSet) MarshalJSON() ([]byte, error) {
+	keys := s.Keys
+	if keys == nil {
+		keys = []JWK{}
+	}
+	return json.Marshal(struct {
+		Keys []JWK `json:"keys"`
+	}{Keys: keys})
+}
+
+// UnmarshalJSON parses a {"keys":[...]} document, skipping the members that cannot be used.
+func (s *JWKSet) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Keys *[]json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Keys == nil {
+		return fmt.Errorf("missing required member keys")
+	}
+	out := JWKSet{}
+	for i, member := range *raw.Keys {
+		var k JWK
+		if err := json.Unmarshal(member, &k); err != nil {
+			out.skipped = append(out.skipped, &SkippedKeyError{Index: i, Err: err})
+			continue
+		}
+		out.Keys = append(out.Keys, k)
+	}
+	*s = out
+	return nil
+}
+
+// SkippedKeyError reports a JWK Set member that was ignored while parsing.
+type SkippedKeyError struct {
+	Index int
+	Err   error
+}
+
+func (e *SkippedKeyError) Error() string {
+	return fmt.Sprintf("key %d skipped: %v", e.Index, e.Err)
+}
+
+func (e *SkippedKeyError) Unwrap() error {
+	return e.Err
+}
+
+// UnsupportedKeyTypeError is returned when a JWK uses a "kty" value this package does not understand.
+type UnsupportedKeyTypeError struct {
+	KeyType string
+}
+
+func (e *UnsupportedKeyTypeError) Error() string {
+	return fmt.Sprintf("unsupported key type %s", e.KeyType)
+}
+
+func marshalRSAPublicKey(raw *jwkJSON, key *rsa.PublicKey) {
+	raw.N = encodeSegment(key.N.Bytes())
+	raw.E = encodeSegment(big.NewInt(int64(key.E)).Bytes())
+}
+
+func marshalECPublicKey(raw *jwkJSON, key *ecdsa.PublicKey) error {
+	crv, err := curveName(key.Curve)
+	if err != nil {
+		return err
+	}
+	size := curveSize(key.Curve)
+	raw.Crv = crv
+	raw.X = encodeSegment(key.X.FillBytes(make([]byte, size)))
+	raw.Y = encodeSegment(key.Y.FillBytes(make([]byte, size)))
+	return nil
+}
+
+func unmarshalRSAKey(raw jwkJSON) (interface{}, error) {
+	n, err := decodeRequiredMember(raw.N, "n")
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeRequiredMember(raw.E, "e")
+	if err != nil {
+		return nil, err
+	}
+	if len(e) > 4 || n[0] == 0 || e[0] == 0 {
+		return nil, fmt.Errorf("invalid RSA public key")
+	}
+	public := rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	if raw.D == "" {
+		if raw.P != "" || raw.Q != "" || raw.DP != "" || raw.DQ != "" || raw.QI != "" {
+			return nil, fmt.Errorf("missing required member d")
+		}
+		return &public, nil
+	}
+	if len(raw.Oth) > 0 {
+		return nil, fmt.Errorf("multi-prime RSA keys are not supported")
+	}
+	members := map[string]string{"d": raw.D, "p": raw.P, "q": raw.Q, "dp": raw.DP, "dq": raw.DQ, "qi": raw.QI}
+	values := make(map[string]*big.Int, len(members))
+	for name, member := range members {
+		b, err := decodeRequiredMember(member, name)
+		if err != nil {
+			return nil, err
+		}
+		values[name] = new(big.Int).SetBytes(b)
+	}
+	private := &rsa.PrivateKey{
+		PublicKey: public,
+		D:         values["d"],
+		Primes:    []*big.Int{values["p"], values["q"]},
+	}
+	if err := private.Validate(); err != nil {
+		return nil, err
+	}
+	private.Precompute()
+	if private.Precomputed.Dp.Cmp(values["dp"]) != 0 ||
+		private.Precomputed.Dq.Cmp(values["dq"]) != 0 ||
+		private.Precomputed.Qinv.Cmp(values["qi"]) != 0 {
+		return nil, fmt.Errorf("invalid RSA private key")
+	}
+	return private, nil
+}
+
+func unmarshalECKey(raw jwkJSON) (interface{}, error) {
+	var curve elliptic.Curve
+	var ecdhCurve ecdh.Curve
+	switch raw.Crv {
+	case CurveP256:
+		curve, ecdhCurve = elliptic.P256(), ecdh.P256()
+	case CurveP384:
+		curve, ecdhCurve = elliptic.P384(), ecdh.P384()
+	case CurveP521:
+		curve, ecdhCurve = elliptic.P521(), ecdh.P521()
+	case "":
+		return nil, fmt.Errorf("missing required member crv")
+	default:
+		return nil, fmt.Errorf("unsupported curve %s", raw.Crv)
+	}
+	size := curveSize(curve)
+	x, err := decodeRequiredMember(raw.X, "x")
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeRequiredMember(raw.Y, "y")
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != size || len(y) != size {
+		return nil, fmt.Errorf("invalid coordinate length for curve %s", raw.Crv)
+	}
+	point := append([]byte{4}, append(x, y...)...)
+	if _, err := ecdhCurve.NewPublicKey(point); err != nil {
+		return nil, fmt.Errorf("point is not on curve %s", raw.Crv)
+	}
+	public := ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	if raw.D == "" {
+		return &public, nil
+	}
+	d, err := base64.RawURLEncoding.Strict().DecodeString(raw.D)
+	if err != nil || len(d) != size {
+		return nil, fmt.Errorf("invalid member d")
+	}
+	private, err := ecdhCurve.NewPrivateKey(d)
+	if err != nil || !bytes.Equal(private.PublicKey().Bytes(), point) {
+		return nil, fmt.Errorf("private key does not match public key")
+	}
+	return &ecdsa.PrivateKey{PublicKey: public, D: new(big.Int).SetBytes(d)}, nil
+}
+
+func unmarshalOKPKey(raw jwkJSON) (interface{}, error) {
+	x, err := decodeRequiredMember(raw.X, "x")
+	if err != nil {
+		return nil, err
+	}
+	switch raw.Crv {
+	case CurveEd25519:
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid member x")
+		}
+		if raw.D == "" {
+			return ed25519.PublicKey(x), nil
+		}
+		d, err := base64.RawURLEncoding.Strict().DecodeString(raw.D)
+		if err != nil || len(d) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid member d")
+		}
+		private := ed25519.NewKeyFromSeed(d)
+		if !bytes.Equal(private.Public().(ed25519.PublicKey), x) {
+			return nil, fmt.Errorf("private key does not match public key")
+		}
+		return private, nil
+	case CurveX25519:
+		public, err := ecdh.X25519().NewPublicKey(x)
+		if err != nil {
+			return nil, fmt.Errorf("invalid member x")
+		}
+		if raw.D == "" {
+			return public, nil
+		}
+		d, err := base64.RawURLEncoding.Strict().DecodeString(raw.D)
+		if err != nil {
+			return nil, fmt.Errorf("invalid member d")
+		}
+		private, err := ecdh.X25519().NewPrivateKey(d)
+		if err != nil {
+			return nil, fmt.Errorf("invalid member d")
+		}
+		if !private.PublicKey().Equal(public) {
+			return nil, fmt.Errorf("private key does not match public key")
+		}
+		return private, nil
+	case "":
+		return nil, fmt.Errorf("missing required member crv")
+	default:
+		return nil, fmt.Errorf("unsupported curve %s", raw.Crv)
+	}
+}
+
+// decodeRequiredMember decodes a base64url member that must be present and non-empty.
+func decodeRequiredMember(value string, name string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing required member %s", name)
+	}
+	b, err := base64.RawURLEncoding.Strict().DecodeString(value)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid member %s", name)
+	}
+	return b, nil
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func curveName(curve elliptic.Curve) (string, error) {
+	switch curve {
+	case elliptic.P256():
+		return CurveP256, nil
+	case elliptic.P384():
+		return CurveP384, nil
+	case elliptic.P521():
+		return CurveP521, nil
+	default:
+		return "", fmt.Errorf("unsupported curve")
+	}
+}
+
+func curveSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
+
+// publicKeysEqual reports whether two public keys hold the same key material.
+func publicKeysEqual(a, b interface{}) bool {
+	switch key := a.(type) {
+	case *rsa.PublicKey:
+		return key.Equal(b)
+	case *ecdsa.PublicKey:
+		return key.Equal(b)
+	case ed25519.PublicKey:
+		return key.Equal(b)
+	case *ecdh.PublicKey:
+		return key.Equal(b)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,296 @@
+package hermes
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJWKEC(t *testing.T) {
+	// RFC 7517 Appendix A.2
+	private := `{"kty":"EC","crv":"P-256","x":"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4","y":"4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM","d":"870MB6gfuTJ4HtUnUvYMyJpr5eUZNP4Bk43bVdj3eAE","use":"enc","kid":"1"}`
+	jwk, err := ParseJWK([]byte(private))
+	assert.NoError(t, err)
+	assert.Equal(t, KeyTypeEC, jwk.KeyType())
+	assert.True(t, jwk.IsPrivate())
+	assert.IsType(t, &ecdsa.PrivateKey{}, jwk.Key)
+
+	out, err := json.Marshal(jwk)
+	assert.NoError(t, err)
+	assert.JSONEq(t, private, string(out))
+
+	public, err := jwk.Public()
+	assert.NoError(t, err)
+	assert.False(t, public.IsPrivate())
+	assert.IsType(t, &ecdsa.PublicKey{}, public.Key)
+
+	// Private key not matching the public point
+	_, err = ParseJWK([]byte(`{"kty":"EC","crv":"P-256","x":"f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU","y":"x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0","d":"870MB6gfuTJ4HtUnUvYMyJpr5eUZNP4Bk43bVdj3eAE"}`))
+	assert.Error(t, err)
+
+	// Point not on the curve
+	_, err = ParseJWK([]byte(`{"kty":"EC","crv":"P-256","x":"f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU","y":"x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI6a0"}`))
+	assert.Error(t, err)
+
+	// Missing y
+	_, err = ParseJWK([]byte(`{"kty":"EC","crv":"P-256","x":"f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU"}`))
+	assert.Error(t, err)
+}
+
+func TestParseJWKRSA(t *testing.T) {
+	// RFC 7517 Appendix A.1
+	public := `{"kty":"RSA","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw","e":"AQAB","alg":"RS256","kid":"2011-04-29"}`
+	jwk, err := ParseJWK([]byte(public))
+	assert.NoError(t, err)
+	assert.Equal(t, KeyTypeRSA, jwk.KeyType())
+	assert.Equal(t, "2011-04-29", jwk.KeyID)
+	assert.Equal(t, "RS256", jwk.Algorithm)
+	assert.Equal(t, 65537, jwk.Key.(*rsa.PublicKey).E)
+	out, err := json.Marshal(jwk)
+	assert.NoError(t, err)
+	assert.JSONEq(t, public, string(out))
+
+	_, err = ParseJWK([]byte(`{"kty":"RSA","e":"AQAB"}`))
+	assert.Error(t, err)
+}
+
+func TestJWKRSAPrivateRoundTrip(t *testing.T) {
+	private, _ := rsa.GenerateKey(rand.Reader, 2048)
+	jwk, err := NewJWK(private)
+	assert.NoError(t, err)
+	jwk.KeyID = "rsa"
+	jwk.Use = KeyUseSignature
+	out, err := json.Marshal(jwk)
+	assert.NoError(t, err)
+
+	parsed, err := ParseJWK(out)
+	assert.NoError(t, err)
+	assert.True(t, private.Equal(parsed.Key))
+	assert.Equal(t, "rsa", parsed.KeyID)
+	assert.Equal(t, KeyUseSignature, parsed.Use)
+
+	// Partial CRT parameters are rejected
+	var m map[string]interface{}
+	json.Unmarshal(out, &m)
+	delete(m, "qi")
+	b, _ := json.Marshal(m)
+	_, err = ParseJWK(b)
+	assert.Error(t, err)
+}
+
+func TestParseJWKOct(t *testing.T) {
+	// RFC 7517 Appendix A.3
+	jwk, err := ParseJWK([]byte(`{"kty":"oct","alg":"A128KW","k":"GawgguFyGrWKav7AX4VKUg"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, KeyTypeOct, jwk.KeyType())
+	assert.Len(t, jwk.Key, 16)
+	_, err = jwk.Public()
+	assert.Error(t, err)
+
+	_, err = ParseJWK([]byte(`{"kty":"oct"}`))
+	assert.Error(t, err)
+}
+
+func TestParseJWKOKP(t *testing.T) {
+	// RFC 8037 Appendix A.1
+	private := `{"kty":"OKP","crv":"Ed25519","d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+	jwk, err := ParseJWK([]byte(private))
+	assert.NoError(t, err)
+	assert.Equal(t, KeyTypeOKP, jwk.KeyType())
+	assert.IsType(t, ed25519.PrivateKey{}, jwk.Key)
+	out, err := json.Marshal(jwk)
+	assert.NoError(t, err)
+	assert.JSONEq(t, private, string(out))
+
+	_, err = ParseJWK([]byte(`{"kty":"OKP","crv":"Ed448","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+	assert.Error(t, err)
+}
+
+func TestParseJWKX25519(t *testing.T) {
+	// RFC 8037 Appendix A.6
+	public := `{"kty":"OKP","crv":"X25519","x":"3p7bfXt9wbTTW2HC7OQ1Nz-DQ8hbeGdNrfx-FG-IK08"}`
+	jwk, err := ParseJWK([]byte(public))
+	assert.NoError(t, err)
+	assert.Equal(t, KeyTypeOKP, jwk.KeyType())
+	assert.IsType(t, &ecdh.PublicKey{}, jwk.Key)
+	out, err := json.Marshal(jwk)
+	assert.NoError(t, err)
+	assert.JSONEq(t, public, string(out))
+
+	private, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	jwk, err = NewJWK(private)
+	assert.NoError(t, err)
+	out, err = json.Marshal(jwk)
+	assert.NoError(t, err)
+	parsed, err := ParseJWK(out)
+	assert.NoError(t, err)
+	assert.True(t, private.Equal(parsed.Key))
+}
+
+func TestParseJWKInvalid(t *testing.T) {
+	_, err := ParseJWK([]byte(`{"k":"GawgguFyGrWKav7AX4VKUg"}`))
+	assert.Error(t, err)
+
+	_, err = ParseJWK([]byte(`{"kty":"unknown"}`))
+	var unsupported *UnsupportedKeyTypeError
+	assert.ErrorAs(t, err, &unsupported)
+
+	_, err = ParseJWK([]byte(`{"kty":"oct","k":"GawgguFyGrWKav7AX4VKUg","key_ops":["sign","sign"]}`))
+	assert.Error(t, err)
+
+	_, err = ParseJWK([]byte(`{"kty":"oct","k":"GawgguFyGrWKav7AX4VKUg","x5t":"AAAA"}`))
+	assert.Error(t, err)
+}
+
+func TestNewJWK(t *testing.T) {
+	private, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	jwk, err := NewJWK(private)
+	assert.NoError(t, err)
+	out, err := json.Marshal(jwk)
+	assert.NoError(t, err)
+	parsed, err := ParseJWK(out)
+	assert.NoError(t, err)
+	assert.True(t, private.Equal(parsed.Key))
+
+	_, err = NewJWK("key")
+	assert.Error(t, err)
+}
+
+func TestParseJWKSet(t *testing.T) {
+	set := `{"keys":[
+		{"kty":"EC","crv":"P-256","x":"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4","y":"4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM","use":"enc","kid":"1"},
+		{"kty":"RSA","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw","e":"AQAB","alg":"RS256","kid":"2011-04-29"},
+		{"kty":"PQC","kid":"future"},
+		{"kty":"oct","kid":"broken"}
+	]}`
+	s, err := ParseJWKSet([]byte(set))
+	assert.NoError(t, err)
+	assert.Len(t, s.Keys, 2)
+	assert.Len(t, s.Skipped(), 2)
+	var unsupported *UnsupportedKeyTypeError
+	assert.ErrorAs(t, s.Skipped()[0], &unsupported)
+	assert.Equal(t, "PQC", unsupported.KeyType)
+	var skipped *SkippedKeyError
+	assert.ErrorAs(t, s.Skipped()[1], &skipped)
+	assert.Equal(t, 3, skipped.Index)
+
+	k, err := s.KeyByID("2011-04-29")
+	assert.NoError(t, err)
+	assert.Equal(t, KeyTypeRSA, k.KeyType())
+	_, err = s.KeyByID("missing")
+	assert.Error(t, err)
+
+	assert.Len(t, s.KeysByType(KeyTypeEC).Keys, 1)
+	assert.Len(t, s.KeysByUse(KeyUseEncryption).Keys, 1)
+	assert.Len(t, s.KeysByAlgorithm("RS256").Keys, 1)
+	assert.Empty(t, s.KeysByType(KeyTypeRSA).KeysByUse(KeyUseEncryption).Keys)
+
+	out, err := json.Marshal(s)
+	assert.NoError(t, err)
+	reparsed, err := ParseJWKSet(out)
+	assert.NoError(t, err)
+	assert.Len(t, reparsed.Keys, 2)
+	assert.Empty(t, reparsed.Skipped())
+
+	_, err = ParseJWKSet([]byte(`{"kty":"oct","k":"GawgguFyGrWKav7AX4VKUg"}`))
+	assert.Error(t, err)
+}
+
+func TestJWKSetPublic(t *testing.T) {
+	private, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s := JWKSet{Keys: []JWK{{Key: private, KeyID: "ec"}, {Key: []byte("secret"), KeyID: "hmac"}}}
+	public := s.Public()
+	assert.Len(t, public.Keys, 1)
+	assert.False(t, public.Keys[0].IsPrivate())
+	assert.Equal(t, "ec", public.Keys[0].KeyID)
+
+	out, err := json.Marshal(JWKSet{})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"keys":[]}`, string(out))
+}
+
+// selfSignedCertificate issues a certificate for the public key of signer, signed by itself.
+func selfSignedCertificate(t *testing.T, signer interface{}, public interface{}) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "hermes-jwt test"},
+		NotBefore:    time.Unix(1300819380, 0),
+		NotAfter:     time.Unix(1300819380, 0).Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, public, signer)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert
+}
+
+func TestJWKX509Certificate(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	cert := selfSignedCertificate(t, ecKey, &ecKey.PublicKey)
+	sha1Sum := sha1.Sum(cert.Raw)
+	sha256Sum := sha256.Sum256(cert.Raw)
+
+	jwk := JWK{
+		Key:                             &ecKey.PublicKey,
+		X509CertificateChain:            []*x509.Certificate{cert},
+		X509CertificateSHA1Thumbprint:   sha1Sum[:],
+		X509CertificateSHA256Thumbprint: sha256Sum[:],
+	}
+	assert.NoError(t, jwk.Validate())
+
+	// The certificate chain and thumbprints survive serialization
+	b, err := json.Marshal(jwk)
+	assert.NoError(t, err)
+	parsed, err := ParseJWK(b)
+	assert.NoError(t, err)
+	assert.Len(t, parsed.X509CertificateChain, 1)
+	assert.Equal(t, cert.Raw, parsed.X509CertificateChain[0].Raw)
+	assert.Equal(t, sha256Sum[:], parsed.X509CertificateSHA256Thumbprint)
+
+	// The certificate must hold the public key of the JWK
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	mismatch := jwk
+	mismatch.Key = &otherKey.PublicKey
+	assert.ErrorContains(t, mismatch.Validate(), "does not match the key")
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	mismatch.Key = &rsaKey.PublicKey
+	assert.ErrorContains(t, mismatch.Validate(), "does not match the key")
+	b, _ = json.Marshal(JWK{Key: &otherKey.PublicKey})
+	var raw map[string]interface{}
+	json.Unmarshal(b, &raw)
+	raw["x5c"] = []string{base64.StdEncoding.EncodeToString(cert.Raw)}
+	b, _ = json.Marshal(raw)
+	_, err = ParseJWK(b)
+	assert.Error(t, err)
+
+	// The thumbprints must be those of the certificate
+	wrong := jwk
+	wrong.X509CertificateSHA1Thumbprint = make([]byte, sha1.Size)
+	assert.ErrorContains(t, wrong.Validate(), "x5t does not match")
+	wrong = jwk
+	wrong.X509CertificateSHA256Thumbprint = make([]byte, sha256.Size)
+	assert.ErrorContains(t, wrong.Validate(), "x5t#S256 does not match")
+	wrong.X509CertificateSHA256Thumbprint = sha1Sum[:]
+	assert.ErrorContains(t, wrong.Validate(), "invalid x5t#S256 length")
+
+	// Ed25519 certificates are matched as well, while symmetric keys cannot carry one
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	edCert := selfSignedCertificate(t, edPrivate, edPublic)
+	assert.NoError(t, JWK{Key: edPublic, X509CertificateChain: []*x509.Certificate{edCert}}.Validate())
+	assert.Error(t, JWK{Key: edPublic, X509CertificateChain: []*x509.Certificate{cert}}.Validate())
+	assert.Error(t, JWK{Key: []byte("secret"), X509CertificateChain: []*x509.Certificate{cert}}.Validate())
+}
@@ -0,0 +1,254 @@
+package hermes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultJWKSRefreshInterval    = 15 * time.Minute
+	defaultJWKSMinRefreshInterval = time.Minute
+	defaultJWKSMaxCacheTTL        = 24 * time.Hour
+	defaultJWKSMaxResponseSize    = 1 << 20
+)
+
+// RemoteJWKSet fetches a JWK Set from a "jwks_uri" endpoint and caches it, honoring the Cache-Control
+// max-age and ETag of the responses. It is a KeyResolver: tokens whose "kid" is not in the cached set
+// cause a refetch, at most once per minimum refresh interval. A RemoteJWKSet is safe for concurrent use.
+type RemoteJWKSet struct {
+	url                string
+	client             *http.Client
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+	maxCacheTTL        time.Duration
+	now                func() time.Time
+
+	// fetching serializes fetches while letting waiters give up when their context is done
+	fetching chan struct{}
+	mu       sync.RWMutex
+	set      JWKSet
+	fetched  bool
+	etag     string
+	expires  time.Time
+	lastTry  time.Time
+}
+
+type RemoteJWKSetOption func(*RemoteJWKSet)
+
+// WithHTTPClient sets the client used to fetch the JWK Set. It defaults to a client with a 10 second timeout.
+func WithHTTPClient(client *http.Client) RemoteJWKSetOption {
+	return func(r *RemoteJWKSet) {
+		r.client = client
+	}
+}
+
+// WithRefreshInterval sets how long a JWK Set is cached when the response carries no Cache-Control max-age.
+func WithRefreshInterval(d time.Duration) RemoteJWKSetOption {
+	return func(r *RemoteJWKSet) {
+		r.refreshInterval = d
+	}
+}
+
+// WithMinRefreshInterval sets the shortest time between two fetches, which rate limits the refetches
+// caused by unknown "kid" values and bounds short Cache-Control lifetimes.
+func WithMinRefreshInterval(d time.Duration) RemoteJWKSetOption {
+	return func(r *RemoteJWKSet) {
+		r.minRefreshInterval = d
+	}
+}
+
+// WithMaxCacheTTL caps the lifetime a Cache-Control max-age can give the cached JWK Set.
+func WithMaxCacheTTL(d time.Duration) RemoteJWKSetOption {
+	return func(r *RemoteJWKSet) {
+		r.maxCacheTTL = d
+	}
+}
+
+func NewRemoteJWKSet(url string, opts ...RemoteJWKSetOption) *RemoteJWKSet {
+	r := &RemoteJWKSet{
+		url:                url,
+		client:             &http.Client{Timeout: 10 * time.Second},
+		refreshInterval:    defaultJWKSRefreshInterval,
+		minRefreshInterval: defaultJWKSMinRefreshInterval,
+		maxCacheTTL:        defaultJWKSMaxCacheTTL,
+		now:                time.Now,
+		fetching:           make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Keys returns the cached JWK Set, fetching it when it has expired. When a refresh fails the
+// previously fetched set is returned.
+func (r *RemoteJWKSet) Keys(ctx context.Context) (JWKSet, error) {
+	r.mu.RLock()
+	set, fetched, expired := r.set, r.fetched, !r.now().Before(r.expires)
+	r.mu.RUnlock()
+	if fetched && !expired {
+		return set, nil
+	}
+	err := r.refresh(ctx, false)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.fetched {
+		return JWKSet{}, err
+	}
+	return r.set, nil
+}
+
+// Refresh fetches the JWK Set now, unless another fetch happened within the minimum refresh interval.
+func (r *RemoteJWKSet) Refresh(ctx context.Context) error {
+	return r.refresh(ctx, true)
+}
+
+// ResolveKeys implements KeyResolver, refetching the JWK Set once when the token names an unknown "kid".
+// Fetches stop when ctx is done.
+func (r *RemoteJWKSet) ResolveKeys(ctx context.Context, header JoseHeader, claims JWTClaimsSet) ([]interface{}, error) {
+	set, err := r.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys, resolveErr := set.ResolveKeys(ctx, header, claims)
+	if resolveErr == nil {
+		return keys, nil
+	}
+	kid, ok := header.Parameter(KeyIDHeader).(string)
+	if !ok {
+		return nil, resolveErr
+	}
+	if _, err := set.KeyByID(kid); err == nil {
+		// the key is known but does not match the header, a new set would not help
+		return nil, resolveErr
+	}
+	if err := r.refresh(ctx, true); err != nil {
+		return nil, resolveErr
+	}
+	r.mu.RLock()
+	set = r.set
+	r.mu.RUnlock()
+	return set.ResolveKeys(ctx, header, claims)
+}
+
+// Start refreshes the JWK Set in the background whenever it expires, until ctx is done.
+func (r *RemoteJWKSet) Start(ctx context.Context) {
+	go func() {
+		r.refresh(ctx, false)
+		for {
+			r.mu.RLock()
+			wait := r.expires.Sub(r.now())
+			r.mu.RUnlock()
+			if wait < r.minRefreshInterval {
+				wait = r.minRefreshInterval
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				r.refresh(ctx, false)
+			}
+		}
+	}()
+}
+
+// refresh fetches the JWK Set, skipping the request when the cached set is still fresh or, for
+// forced refreshes, when the last attempt is more recent than the minimum refresh interval.
+func (r *RemoteJWKSet) refresh(ctx context.Context, force bool) error {
+	select {
+	case r.fetching <- struct{}{}:
+		defer func() { <-r.fetching }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	r.mu.Lock()
+	now := r.now()
+	fetched, etag := r.fetched, r.etag
+	fresh := fetched && now.Before(r.expires)
+	limited := !r.lastTry.IsZero() && now.Sub(r.lastTry) < r.minRefreshInterval
+	if !limited && (force || !fresh) {
+		r.lastTry = now
+	}
+	r.mu.Unlock()
+	if limited || (!force && fresh) {
+		if !fetched {
+			return fmt.Errorf("JWK Set from %s is not available", r.url)
+		}
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", JWK_SET_MEDIA_TYPE+", application/json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	ttl := r.cacheTTL(resp.Header.Get("Cache-Control"))
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		r.mu.Lock()
+		r.expires = now.Add(ttl)
+		r.mu.Unlock()
+		return nil
+	case http.StatusOK:
+	default:
+		return fmt.Errorf("fetching JWK Set from %s: unexpected status %s", r.url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, defaultJWKSMaxResponseSize+1))
+	if err != nil {
+		return err
+	}
+	if len(body) > defaultJWKSMaxResponseSize {
+		return fmt.Errorf("fetching JWK Set from %s: response too large", r.url)
+	}
+	set, err := ParseJWKSet(body)
+	if err != nil {
+		return fmt.Errorf("fetching JWK Set from %s: %w", r.url, err)
+	}
+	r.mu.Lock()
+	r.set = set
+	r.fetched = true
+	r.etag = resp.Header.Get("ETag")
+	r.expires = now.Add(ttl)
+	r.mu.Unlock()
+	return nil
+}
+
+// cacheTTL derives the cache lifetime from a Cache-Control header, bounded by the minimum refresh
+// interval and the maximum cache TTL.
+func (r *RemoteJWKSet) cacheTTL(cacheControl string) time.Duration {
+	ttl := r.refreshInterval
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store" || directive == "no-cache":
+			return r.minRefreshInterval
+		case strings.HasPrefix(directive, "max-age="):
+			if seconds, err := strconv.Atoi(strings.Trim(directive[len("max-age="):], `"`)); err == nil && seconds >= 0 {
+				ttl = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	if ttl < r.minRefreshInterval {
+		ttl = r.minRefreshInterval
+	}
+	if r.maxCacheTTL > 0 && ttl > r.maxCacheTTL {
+		ttl = r.maxCacheTTL
+	}
+	return ttl
+}
@@ -0,0 +1,246 @@
+package hermes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jwksServer struct {
+	mu           sync.Mutex
+	set          JWKSet
+	etag         string
+	cacheControl string
+	requests     atomic.Int32
+	notModified  atomic.Int32
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cacheControl != "" {
+		w.Header().Set("Cache-Control", s.cacheControl)
+	}
+	if s.etag != "" {
+		w.Header().Set("ETag", s.etag)
+		if r.Header.Get("If-None-Match") == s.etag {
+			s.notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", JWK_SET_MEDIA_TYPE)
+	json.NewEncoder(w).Encode(s.set)
+}
+
+func (s *jwksServer) setKeys(set JWKSet, etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set = set
+	s.etag = etag
+}
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestRemoteJWKSetCaching(t *testing.T) {
+	key1 := []byte("first secret key of at least 32 bytes")
+	server := &jwksServer{cacheControl: "public, max-age=300"}
+	server.setKeys(JWKSet{Keys: []JWK{{Key: key1, KeyID: "1"}}}, `"v1"`)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	remote := NewRemoteJWKSet(ts.URL, WithHTTPClient(ts.Client()))
+	remote.now = clock.Now
+
+	set, err := remote.Keys(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, set.Keys, 1)
+	_, err = remote.Keys(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), server.requests.Load())
+
+	// After max-age the set is revalidated with the ETag
+	clock.Advance(301 * time.Second)
+	set, err = remote.Keys(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, set.Keys, 1)
+	assert.Equal(t, int32(2), server.requests.Load())
+	assert.Equal(t, int32(1), server.notModified.Load())
+
+	// A failing endpoint keeps serving the stale set
+	ts.Close()
+	clock.Advance(301 * time.Second)
+	set, err = remote.Keys(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, set.Keys, 1)
+}
+
+func TestRemoteJWKSetUnknownKeyID(t *testing.T) {
+	key1 := []byte("first secret key of at least 32 bytes")
+	key2 := []byte("second secret key of at least 32 bytes")
+	server := &jwksServer{}
+	server.setKeys(JWKSet{Keys: []JWK{{Key: key1, KeyID: "1"}}}, "")
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	remote := NewRemoteJWKSet(ts.URL, WithHTTPClient(ts.Client()), WithMinRefreshInterval(time.Minute))
+	remote.now = clock.Now
+
+	compact, _, _ := NewBuilder().HeaderParameter("kid", "1").Claim("sub", "joe").Sign("HS256", key1)
+	jwt, _ := ParseJWS(compact)
+	assert.NoError(t, jwt.VerifyWith(context.Background(), remote))
+	assert.Equal(t, SignatureVerified, jwt.State())
+	assert.Equal(t, int32(1), server.requests.Load())
+
+	// A rotated key is picked up once the rate limit allows a refetch
+	server.setKeys(JWKSet{Keys: []JWK{{Key: key1, KeyID: "1"}, {Key: key2, KeyID: "2"}}}, "")
+	compact, _, _ = NewBuilder().HeaderParameter("kid", "2").Claim("sub", "joe").Sign("HS256", key2)
+	jwt, _ = ParseJWS(compact)
+	assert.Error(t, jwt.VerifyWith(context.Background(), remote))
+	assert.Equal(t, int32(1), server.requests.Load())
+
+	clock.Advance(time.Minute)
+	jwt, _ = ParseJWS(compact)
+	assert.NoError(t, jwt.VerifyWith(context.Background(), remote))
+	assert.Equal(t, SignatureVerified, jwt.State())
+	assert.Equal(t, int32(2), server.requests.Load())
+
+	// Unknown kids do not cause a refetch within the rate limit
+	compact, _, _ = NewBuilder().HeaderParameter("kid", "3").Claim("sub", "joe").Sign("HS256", key2)
+	for i := 0; i < 5; i++ {
+		jwt, _ = ParseJWS(compact)
+		assert.Error(t, jwt.VerifyWith(context.Background(), remote))
+	}
+	assert.Equal(t, int32(2), server.requests.Load())
+}
+
+func TestRemoteJWKSetRefresh(t *testing.T) {
+	key1 := []byte("first secret key of at least 32 bytes")
+	key2 := []byte("second secret key of at least 32 bytes")
+	server := &jwksServer{cacheControl: "public, max-age=3600"}
+	server.setKeys(JWKSet{Keys: []JWK{{Key: key1, KeyID: "1"}}}, "")
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	remote := NewRemoteJWKSet(ts.URL, WithHTTPClient(ts.Client()), WithMinRefreshInterval(time.Minute))
+	remote.now = clock.Now
+
+	assert.NoError(t, remote.Refresh(context.Background()))
+	assert.Equal(t, int32(1), server.requests.Load())
+
+	// Forced refreshes within the minimum refresh interval keep the cached set
+	server.setKeys(JWKSet{Keys: []JWK{{Key: key1, KeyID: "1"}, {Key: key2, KeyID: "2"}}}, "")
+	assert.NoError(t, remote.Refresh(context.Background()))
+	clock.Advance(59 * time.Second)
+	assert.NoError(t, remote.Refresh(context.Background()))
+	assert.Equal(t, int32(1), server.requests.Load())
+	set, _ := remote.Keys(context.Background())
+	assert.Len(t, set.Keys, 1)
+
+	// Once the interval has passed the set is refetched, although the cached one has not expired
+	clock.Advance(time.Second)
+	assert.NoError(t, remote.Refresh(context.Background()))
+	assert.Equal(t, int32(2), server.requests.Load())
+	set, _ = remote.Keys(context.Background())
+	assert.Len(t, set.Keys, 2)
+	assert.Equal(t, int32(2), server.requests.Load())
+}
+
+func TestRemoteJWKSetErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	remote := NewRemoteJWKSet(ts.URL, WithHTTPClient(ts.Client()))
+	_, err := remote.Keys(context.Background())
+	assert.ErrorContains(t, err, "unexpected status")
+	_, err = remote.Keys(context.Background())
+	assert.Error(t, err)
+}
+
+func TestRemoteJWKSetCacheTTL(t *testing.T) {
+	remote := NewRemoteJWKSet("https://example.com/jwks", WithRefreshInterval(10*time.Minute), WithMinRefreshInterval(time.Minute), WithMaxCacheTTL(time.Hour))
+	assert.Equal(t, 10*time.Minute, remote.cacheTTL(""))
+	assert.Equal(t, 5*time.Minute, remote.cacheTTL("public, max-age=300"))
+	assert.Equal(t, time.Minute, remote.cacheTTL("max-age=5"))
+	assert.Equal(t, time.Hour, remote.cacheTTL("max-age=86400"))
+	assert.Equal(t, time.Minute, remote.cacheTTL("no-store"))
+	assert.Equal(t, 10*time.Minute, remote.cacheTTL("max-age=abc"))
+}
+
+func TestRemoteJWKSetStart(t *testing.T) {
+	key1 := []byte("first secret key of at least 32 bytes")
+	server := &jwksServer{cacheControl: "no-cache"}
+	server.setKeys(JWKSet{Keys: []JWK{{Key: key1, KeyID: "1"}}}, "")
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	remote := NewRemoteJWKSet(ts.URL, WithHTTPClient(ts.Client()), WithMinRefreshInterval(10*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	remote.Start(ctx)
+	assert.Eventually(t, func() bool { return server.requests.Load() >= 3 }, 2*time.Second, 5*time.Millisecond)
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	requests := server.requests.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, requests, server.requests.Load())
+}
+
+func TestRemoteJWKSetContext(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	remote := NewRemoteJWKSet(ts.URL, WithHTTPClient(ts.Client()), WithMinRefreshInterval(0))
+	header := JoseHeader{"alg": "HS256", "kid": "1"}
+
+	// A slow endpoint does not hold a request past its deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := remote.ResolveKeys(ctx, header, JWTClaimsSet{})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// Requests waiting behind an in-flight fetch give up when their context is done
+	go remote.Keys(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	_, err = remote.ResolveKeys(ctx, header, JWTClaimsSet{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -2,6 +2,9 @@
 package hermes
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -20,12 +23,39 @@ func (j JWT) Sign(key interface{}) ([]byte, error) {
 		return cryptography.HMACSign(j.Algorithm(), key, jwsSigningInput)
 	case cryptography.AlgorithmRS256, cryptography.AlgorithmRS384, cryptography.AlgorithmRS512:
 		return cryptography.RSASign(j.Algorithm(), key, jwsSigningInput)
+	case cryptography.AlgorithmES256, cryptography.AlgorithmES384, cryptography.AlgorithmES512:
+		return cryptography.ECDSASign(j.Algorithm(), key, jwsSigningInput)
+	case cryptography.AlgorithmPS256, cryptography.AlgorithmPS384, cryptography.AlgorithmPS512:
+		return cryptography.RSAPSSSign(j.Algorithm(), key, jwsSigningInput)
+	case cryptography.AlgorithmEdDSA:
+		return cryptography.EdDSASign(j.Algorithm(), key, jwsSigningInput)
 	default:
 		return nil, fmt.Errorf("unsupported algorithm")
 	}
 }
 
+// signed signs the JWT and returns a copy holding the signature and compact serialization in SignatureVerified state.
+func (j JWT) signed(key interface{}) (JWT, error) {
+	if _, err := j.payload.toJSON(); err != nil {
+		return JWT{}, err
+	}
+	signature, err := j.Sign(key)
+	if err != nil {
+		return JWT{}, err
+	}
+	j.signature = signature
+	j.compact = j.header.ToBase64URL() + "." + j.payload.ToBase64URL() + "." + base64.RawURLEncoding.EncodeToString(signature)
+	j.state = SignatureVerified
+	return j, nil
+}
+
+// Verify checks the signature of a JWT parsed with ParseJWS. The key must belong to the family of the
+// "alg" header: a []byte for HMAC, an *rsa.PublicKey for RSA, an *ecdsa.PublicKey for ECDSA or an
+// ed25519.PublicKey for EdDSA. A JWK is accepted when its "kty", "alg", "use" and "key_ops" permit the algorithm.
 func (j *JWT) Verify(key interface{}) error {
+	if j.header.Algorithm() == cryptography.AlgorithmNone {
+		return fmt.Errorf("unsecured JWT has no signature to verify")
+	}
 	parts := strings.Split(j.compact, ".")
 	if len(parts) != 3 && j.IsJWS() {
 		j.state = InvalidJWT
@@ -42,13 +72,23 @@ func (j *JWT) Verify(key interface{}) error {
 		}
 	}
 	jwsSigningInput := parts[0] + "." + parts[1]
+	key, err := verificationKey(j.header.Algorithm(), key)
+	if err != nil {
+		j.state = SignatureInvalid
+		return err
+	}
 	b := false
-	var err error
 	switch j.header.Algorithm() {
 	case cryptography.AlgorithmHS256, cryptography.AlgorithmHS384, cryptography.AlgorithmHS512:
 		b, err = cryptography.HMACVerify(j.Algorithm(), key, jwsSigningInput, j.signature)
 	case cryptography.AlgorithmRS256, cryptography.AlgorithmRS384, cryptography.AlgorithmRS512:
 		b, err = cryptography.RSAVerify(j.Algorithm(), key, jwsSigningInput, j.signature)
+	case cryptography.AlgorithmES256, cryptography.AlgorithmES384, cryptography.AlgorithmES512:
+		b, err = cryptography.ECDSAVerify(j.Algorithm(), key, jwsSigningInput, j.signature)
+	case cryptography.AlgorithmPS256, cryptography.AlgorithmPS384, cryptography.AlgorithmPS512:
+		b, err = cryptography.RSAPSSVerify(j.Algorithm(), key, jwsSigningInput, j.signature)
+	case cryptography.AlgorithmEdDSA:
+		b, err = cryptography.EdDSAVerify(j.Algorithm(), key, jwsSigningInput, j.signature)
 	default:
 		j.state = SignatureInvalid
 		return fmt.Errorf("unsupported algorithm")
@@ -65,7 +105,124 @@ func (j *JWT) Verify(key interface{}) error {
 	return nil
 }
 
-func ParseJWS(jwt string) (JWT, error) {
+// verificationKey binds the key to the family of alg, unwrapping JWK values, so that a key is never
+// used with an algorithm it was not meant for.
+func verificationKey(alg string, key interface{}) (interface{}, error) {
+	if k, ok := key.(*JWK); ok && k != nil {
+		key = *k
+	}
+	if k, ok := key.(JWK); ok {
+		if k.Algorithm != "" && k.Algorithm != alg {
+			return nil, fmt.Errorf("key is bound to algorithm %s, not %s", k.Algorithm, alg)
+		}
+		if k.Use != "" && k.Use != KeyUseSignature {
+			return nil, fmt.Errorf("key is not intended for signatures")
+		}
+		if len(k.KeyOperations) > 0 && !containsString(k.KeyOperations, KeyOperationVerify) {
+			return nil, fmt.Errorf("key does not permit the verify operation")
+		}
+		if k.KeyType() == KeyTypeOct {
+			key = k.Key
+		} else {
+			public, err := k.PublicKey()
+			if err != nil {
+				return nil, err
+			}
+			key = public
+		}
+	}
+	var ok bool
+	switch alg {
+	case cryptography.AlgorithmHS256, cryptography.AlgorithmHS384, cryptography.AlgorithmHS512:
+		var b []byte
+		b, ok = key.([]byte)
+		ok = ok && len(b) > 0
+	case cryptography.AlgorithmRS256, cryptography.AlgorithmRS384, cryptography.AlgorithmRS512,
+		cryptography.AlgorithmPS256, cryptography.AlgorithmPS384, cryptography.AlgorithmPS512:
+		_, ok = key.(*rsa.PublicKey)
+	case cryptography.AlgorithmES256, cryptography.AlgorithmES384, cryptography.AlgorithmES512:
+		_, ok = key.(*ecdsa.PublicKey)
+	case cryptography.AlgorithmEdDSA:
+		_, ok = key.(ed25519.PublicKey)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm")
+	}
+	if !ok {
+		return nil, fmt.Errorf("key of type %T cannot be used with algorithm %s", key, alg)
+	}
+	return key, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseOption configures the checks ParseJWS applies before a token is accepted.
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	algorithms     []string
+	allowUnsecured bool
+	jweOptions     []JWEOption
+	useNumber      bool
+}
+
+// WithAllowedAlgorithms restricts the "alg" header values ParseJWS accepts. Callers should always name the
+// algorithms they expect, since the header is chosen by whoever produced the token.
+func WithAllowedAlgorithms(algorithms ...string) ParseOption {
+	return func(o *parseOptions) {
+		o.algorithms = append(o.algorithms, algorithms...)
+	}
+}
+
+// AllowUnsecured lets ParseJWS accept unsecured JWTs using the "none" algorithm, which are rejected by default.
+// Such tokens are returned in the Unsecured state and carry no integrity protection.
+func AllowUnsecured() ParseOption {
+	return func(o *parseOptions) {
+		o.allowUnsecured = true
+	}
+}
+
+// UseJSONNumber makes ParseJWS decode numeric claims as json.Number instead of float64, keeping
+// the exact value of integers above 2^53.
+func UseJSONNumber() ParseOption {
+	return func(o *parseOptions) {
+		o.useNumber = true
+	}
+}
+
+// WithDecryptOptions sets the options ParseNestedJWT passes to Decrypt for the outer JWE. ParseJWS ignores them.
+func WithDecryptOptions(opts ...JWEOption) ParseOption {
+	return func(o *parseOptions) {
+		o.jweOptions = append(o.jweOptions, opts...)
+	}
+}
+
+func newParseOptions(opts []ParseOption) parseOptions {
+	var o parseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (o parseOptions) checkAlgorithm(alg string) error {
+	if alg == cryptography.AlgorithmNone && !o.allowUnsecured {
+		return fmt.Errorf("unsecured JWTs are not allowed")
+	}
+	if len(o.algorithms) > 0 && !containsString(o.algorithms, alg) {
+		return fmt.Errorf("algorithm %s is not allowed", alg)
+	}
+	return nil
+}
+
+func ParseJWS(jwt string, opts ...ParseOption) (JWT, error) {
+	o := newParseOptions(opts)
 	if jwt == "" {
 		return JWT{}, fmt.Errorf("empty JWT")
 	}
@@ -84,15 +241,29 @@ func ParseJWS(jwt string) (JWT, error) {
 	if !IsJWS(h.Algorithm()) {
 		return JWT{}, fmt.Errorf("not a JWS")
 	}
+	if err := o.checkAlgorithm(h.Algorithm()); err != nil {
+		return JWT{}, err
+	}
 	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
 	if err != nil {
 		return JWT{}, err
 	}
-	var claimsMap map[string]interface{} = make(map[string]interface{})
-	if err := json.Unmarshal(payload, &claimsMap); err != nil {
+	claims, err := decodeClaims(payload, o.useNumber)
+	if err != nil {
 		return JWT{}, err
 	}
-	claims := NewJWTClaimsSet(claimsMap)
+	if h.Algorithm() == cryptography.AlgorithmNone {
+		// RFC 7519 §6.1: the JWS Signature of an unsecured JWT is the empty string
+		if parts[2] != "" {
+			return JWT{}, fmt.Errorf("unsecured JWT must have an empty signature")
+		}
+		return JWT{
+			header:  h,
+			payload: claims,
+			compact: jwt,
+			state:   Unsecured,
+		}, nil
+	}
 	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
 	if err != nil {
 		return JWT{}, err
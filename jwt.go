@@ -5,10 +5,9 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-)
 
-type StringOrURI string
-type NumericDate int64
+	cryptography "github.com/prulloac/hermes-jwt/cryptography"
+)
 
 const JWT_MEDIA_TYPE = "application/jwt"
 const JWT_URN = "urn:ietf:params:oauth:token-type:jwt"
@@ -41,25 +40,49 @@ const (
 )
 
 type JWT struct {
-	header    JoseHeader
-	payload   JWTClaimsSet
-	signature []byte
-	compact   string
-	state     JWTState
+	header       JoseHeader
+	payload      JWTClaimsSet
+	signature    []byte
+	encryptedKey []byte
+	iv           []byte
+	ciphertext   []byte
+	tag          []byte
+	compact      string
+	state        JWTState
+}
+
+// NewJWT creates a JWT from a JOSE header and a claims set, ready to be signed or encrypted.
+func NewJWT(header JoseHeader, payload JWTClaimsSet) JWT {
+	return JWT{header: header, payload: payload, state: Unsecured}
+}
+
+func (j JWT) Header() JoseHeader {
+	return j.header
+}
+
+func (j JWT) Claims() JWTClaimsSet {
+	return j.payload
 }
 
 func (j JWT) State() JWTState {
 	return j.state
 }
 
+// String returns the compact serialization of a parsed or built JWT, or assembles one from its header, claims and signature.
 func (j JWT) String() string {
+	if j.compact != "" {
+		return j.compact
+	}
 	out := j.header.ToBase64URL() + "." +
 		j.payload.ToBase64URL()
 	if len(j.signature) == 0 {
+		if j.header.Algorithm() == cryptography.AlgorithmNone {
+			return out + "."
+		}
 		return out
 	}
 	return out + "." +
-		base64.URLEncoding.EncodeToString(j.signature)
+		base64.RawURLEncoding.EncodeToString(j.signature)
 }
 
 func (j JWT) IsSecured() bool {
@@ -81,17 +104,21 @@ func NewJWTClaimsSet(m map[string]interface{}) JWTClaimsSet {
 }
 
 func (j JWTClaimsSet) ToBase64URL() string {
-	var m map[string]interface{} = make(map[string]interface{})
-	for _, c := range j.Claims {
-		m[c.Name] = c.Value
-	}
-	b, err := json.Marshal(m)
+	b, err := j.toJSON()
 	if err != nil {
 		panic(err)
 	}
 	return base64.RawURLEncoding.EncodeToString(b)
 }
 
+func (j JWTClaimsSet) toJSON() ([]byte, error) {
+	var m map[string]interface{} = make(map[string]interface{})
+	for _, c := range j.Claims {
+		m[c.Name] = c.Value
+	}
+	return json.Marshal(m)
+}
+
 func (j JWTClaimsSet) GetClaim(name string) (Claim, error) {
 	for _, c := range j.Claims {
 		if c.Name == name {
@@ -160,11 +187,12 @@ func (j JoseHeader) ToBase64URL() string {
 	if err != nil {
 		panic(err)
 	}
-	return base64.URLEncoding.EncodeToString(b)
+	return base64.RawURLEncoding.EncodeToString(b)
 }
 
 func (j JoseHeader) Algorithm() string {
-	return j.Parameter("alg").(string)
+	alg, _ := j.Parameter("alg").(string)
+	return alg
 }
 
 func (j JoseHeader) Parameter(key string) interface{} {
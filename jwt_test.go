@@ -12,7 +12,7 @@ func TestJWTToString(t *testing.T) {
 	signature := []byte("signature")
 
 	jwt := JWT{header: header, payload: payload, signature: signature}
-	expected := header.ToBase64URL() + "." + payload.ToBase64URL() + "." + base64.URLEncoding.EncodeToString(signature)
+	expected := header.ToBase64URL() + "." + payload.ToBase64URL() + "." + base64.RawURLEncoding.EncodeToString(signature)
 
 	if jwt.String() != expected {
 		t.Errorf("expected %s, got %s", expected, jwt.String())
@@ -22,7 +22,7 @@ func TestJWTToString(t *testing.T) {
 func TestJoseHeaderToBase64URL(t *testing.T) {
 	header := JoseHeader{"alg": "HS256", "typ": "JWT"}
 	expected, _ := json.Marshal(header)
-	expectedStr := base64.URLEncoding.EncodeToString(expected)
+	expectedStr := base64.RawURLEncoding.EncodeToString(expected)
 
 	if header.ToBase64URL() != expectedStr {
 		t.Errorf("expected %s, got %s", expectedStr, header.ToBase64URL())
@@ -0,0 +1,339 @@
+// Reference: https://datatracker.ietf.org/doc/html/rfc6750
+package hermes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	ErrorCodeInvalidRequest    = "invalid_request"
+	ErrorCodeInvalidToken      = "invalid_token"
+	ErrorCodeInsufficientScope = "insufficient_scope"
+)
+
+// Descriptions sent in the "error_description" of invalid_token challenges. They are deliberately
+// generic; the detailed error is only handed to the error log.
+const (
+	descriptionTokenMalformed     = "token malformed"
+	descriptionSignatureInvalid   = "signature invalid"
+	descriptionTokenExpired       = "token expired"
+	descriptionTokenNotYetValid   = "token not yet valid"
+	descriptionTokenClaimsInvalid = "token claims invalid"
+	descriptionTokenRejected      = "token rejected"
+)
+
+// Descriptions sent in the "error_description" of insufficient_scope challenges. Like the ones above,
+// they never reveal the policy being enforced.
+const (
+	descriptionInsufficientScope     = "insufficient scope"
+	descriptionInsufficientPrivilege = "insufficient privileges"
+)
+
+// TokenError is returned by Middleware.Authenticate. Description is a generic message that is safe to
+// send to clients, while Err holds the detailed cause, which may name keys, URLs or claims.
+type TokenError struct {
+	Description string
+	Err         error
+}
+
+func (e *TokenError) Error() string {
+	return e.Description + ": " + e.Err.Error()
+}
+
+func (e *TokenError) Unwrap() error {
+	return e.Err
+}
+
+// errNoToken is returned by a TokenExtractor when the request does not carry a token.
+var errNoToken = errors.New("no token")
+
+// TokenExtractor reads a bearer token from a request. It returns an empty token when the request does
+// not use its transmission method, and an error when the request uses it incorrectly.
+type TokenExtractor func(r *http.Request) (string, error)
+
+// FromAuthorizationHeader reads the token of an "Authorization: Bearer" header, RFC 6750 §2.1.
+func FromAuthorizationHeader(r *http.Request) (string, error) {
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		return "", nil
+	}
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", nil
+	}
+	token = strings.TrimSpace(token)
+	if !ok || token == "" || strings.ContainsAny(token, " \t") {
+		return "", fmt.Errorf("malformed Authorization header")
+	}
+	return token, nil
+}
+
+// FromCookie reads the token from the named cookie.
+func FromCookie(name string) TokenExtractor {
+	return func(r *http.Request) (string, error) {
+		cookie, err := r.Cookie(name)
+		if err != nil {
+			return "", nil
+		}
+		return cookie.Value, nil
+	}
+}
+
+// FromQuery reads the token from the named URI query parameter, such as "access_token" per RFC 6750 §2.3.
+// Tokens in URIs are prone to leak through logs and should only be accepted when no other method is possible.
+func FromQuery(name string) TokenExtractor {
+	return func(r *http.Request) (string, error) {
+		values, ok := r.URL.Query()[name]
+		if !ok {
+			return "", nil
+		}
+		if len(values) != 1 || values[0] == "" {
+			return "", fmt.Errorf("malformed %s query parameter", name)
+		}
+		return values[0], nil
+	}
+}
+
+type contextKey struct{}
+
+// ContextWithJWT returns a copy of ctx carrying a verified JWT.
+func ContextWithJWT(ctx context.Context, jwt JWT) context.Context {
+	return context.WithValue(ctx, contextKey{}, jwt)
+}
+
+// JWTFromContext returns the JWT stored by the Middleware, if any.
+func JWTFromContext(ctx context.Context) (JWT, bool) {
+	jwt, ok := ctx.Value(contextKey{}).(JWT)
+	return jwt, ok
+}
+
+// Middleware authenticates requests carrying bearer tokens. Requests whose token is verified and valid
+// reach the next handler with the JWT in their context; the others are answered with an RFC 6750 error.
+type Middleware struct {
+	resolver     KeyResolver
+	parseOptions []ParseOption
+	validator    Validator
+	extractors   []TokenExtractor
+	policies     []Policy
+	realm        string
+	errorLog     func(r *http.Request, err error)
+}
+
+type MiddlewareOption func(*Middleware)
+
+// WithParseOptions sets the options passed to ParseJWS. They must include WithAllowedAlgorithms.
+func WithParseOptions(opts ...ParseOption) MiddlewareOption {
+	return func(m *Middleware) {
+		m.parseOptions = append(m.parseOptions, opts...)
+	}
+}
+
+// WithValidator sets the validator applied to the claims once the signature is verified.
+func WithValidator(v Validator) MiddlewareOption {
+	return func(m *Middleware) {
+		m.validator = v
+	}
+}
+
+// WithTokenExtractors replaces the token transmission methods accepted, which default to the Authorization header.
+func WithTokenExtractors(extractors ...TokenExtractor) MiddlewareOption {
+	return func(m *Middleware) {
+		m.extractors = extractors
+	}
+}
+
+// WithRequiredScopes answers with insufficient_scope unless the "scope" claim holds every given scope.
+func WithRequiredScopes(scopes ...string) MiddlewareOption {
+	return WithPolicy(RequireScopes(scopes...))
+}
+
+// WithPolicy answers with insufficient_scope unless the claims satisfy the policy.
+func WithPolicy(p Policy) MiddlewareOption {
+	return func(m *Middleware) {
+		m.policies = append(m.policies, p)
+	}
+}
+
+// WithErrorLog sets a function receiving the detailed error of every rejected request. The responses
+// themselves only carry generic descriptions.
+func WithErrorLog(log func(r *http.Request, err error)) MiddlewareOption {
+	return func(m *Middleware) {
+		m.errorLog = log
+	}
+}
+
+// WithRealm sets the "realm" attribute of the WWW-Authenticate challenges.
+func WithRealm(realm string) MiddlewareOption {
+	return func(m *Middleware) {
+		m.realm = realm
+	}
+}
+
+// NewMiddleware returns a Middleware verifying tokens with the keys of resolver. The algorithms it accepts
+// must be listed with WithParseOptions(WithAllowedAlgorithms(...)); without an allow-list every token is
+// rejected, since trusting the "alg" header of the token opens the door to algorithm confusion.
+func NewMiddleware(resolver KeyResolver, opts ...MiddlewareOption) *Middleware {
+	m := &Middleware{
+		resolver:   resolver,
+		validator:  NewValidator(),
+		extractors: []TokenExtractor{FromAuthorizationHeader},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Handler wraps next with bearer token authentication.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := m.extract(r)
+		if errors.Is(err, errNoToken) {
+			m.challenge(w, http.StatusUnauthorized, "", "", "")
+			return
+		}
+		if err != nil {
+			m.logError(r, err)
+			m.challenge(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error(), "")
+			return
+		}
+		jwt, err := m.Authenticate(r.Context(), token)
+		if err != nil {
+			m.logError(r, err)
+			description := descriptionTokenRejected
+			var tokenErr *TokenError
+			if errors.As(err, &tokenErr) {
+				description = tokenErr.Description
+			}
+			m.challenge(w, http.StatusUnauthorized, ErrorCodeInvalidToken, description, "")
+			return
+		}
+		if err := And(m.policies...).Authorize(jwt); err != nil {
+			m.forbidden(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ContextWithJWT(r.Context(), jwt)))
+	})
+}
+
+// Require wraps next so that only requests whose JWT, stored by Handler, satisfies the policy reach it.
+// It lets routes behind a shared Middleware demand their own scopes or roles.
+func (m *Middleware) Require(p Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			jwt, ok := JWTFromContext(r.Context())
+			if !ok {
+				m.challenge(w, http.StatusUnauthorized, "", "", "")
+				return
+			}
+			if err := p.Authorize(jwt); err != nil {
+				m.forbidden(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forbidden answers a policy failure with insufficient_scope. Only the scopes required by a failed
+// RequireScopes reach the client; any other detail of the policy is left to the error log.
+func (m *Middleware) forbidden(w http.ResponseWriter, r *http.Request, err error) {
+	m.logError(r, err)
+	description, scope := descriptionInsufficientPrivilege, ""
+	var scopeErr *InsufficientScopeError
+	if errors.As(err, &scopeErr) {
+		description, scope = descriptionInsufficientScope, strings.Join(scopeErr.Required, " ")
+	}
+	m.challenge(w, http.StatusForbidden, ErrorCodeInsufficientScope, description, scope)
+}
+
+// Authenticate parses a compact JWS, verifies it with the key resolver and validates its claims.
+// Failures are reported as a *TokenError.
+func (m *Middleware) Authenticate(ctx context.Context, token string) (JWT, error) {
+	if len(newParseOptions(m.parseOptions).algorithms) == 0 {
+		return JWT{}, &TokenError{Description: descriptionTokenRejected, Err: fmt.Errorf("no allowed algorithms configured")}
+	}
+	jwt, err := ParseJWS(token, m.parseOptions...)
+	if err != nil {
+		return JWT{}, &TokenError{Description: descriptionTokenMalformed, Err: err}
+	}
+	if err := jwt.VerifyWith(ctx, m.resolver); err != nil {
+		return JWT{}, &TokenError{Description: descriptionSignatureInvalid, Err: err}
+	}
+	if err := m.validator.Validate(jwt.Claims()); err != nil {
+		description := descriptionTokenClaimsInvalid
+		switch {
+		case errors.Is(err, ErrTokenExpired), errors.Is(err, ErrTokenTooOld):
+			description = descriptionTokenExpired
+		case errors.Is(err, ErrTokenNotYetValid), errors.Is(err, ErrTokenIssuedInFuture):
+			description = descriptionTokenNotYetValid
+		}
+		return JWT{}, &TokenError{Description: description, Err: err}
+	}
+	return jwt, nil
+}
+
+func (m *Middleware) logError(r *http.Request, err error) {
+	if m.errorLog != nil {
+		m.errorLog(r, err)
+	}
+}
+
+// extract reads the token, rejecting requests that use more than one transmission method as RFC 6750 §2 requires.
+func (m *Middleware) extract(r *http.Request) (string, error) {
+	var token string
+	for _, extract := range m.extractors {
+		t, err := extract(r)
+		if err != nil {
+			return "", err
+		}
+		if t == "" {
+			continue
+		}
+		if token != "" {
+			return "", fmt.Errorf("more than one method used to transmit the token")
+		}
+		token = t
+	}
+	if token == "" {
+		return "", errNoToken
+	}
+	return token, nil
+}
+
+// challenge writes an error response with a WWW-Authenticate header per RFC 6750 §3.
+func (m *Middleware) challenge(w http.ResponseWriter, status int, code string, description string, scope string) {
+	var params []string
+	if m.realm != "" {
+		params = append(params, fmt.Sprintf("realm=%q", authParamValue(m.realm)))
+	}
+	if code != "" {
+		params = append(params, fmt.Sprintf("error=%q", code))
+	}
+	if description != "" {
+		params = append(params, fmt.Sprintf("error_description=%q", authParamValue(description)))
+	}
+	if scope != "" {
+		params = append(params, fmt.Sprintf("scope=%q", authParamValue(scope)))
+	}
+	challenge := "Bearer"
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, http.StatusText(status), status)
+}
+
+// authParamValue drops the characters RFC 6750 §3 excludes from attribute values, including '"' and '\'.
+func authParamValue(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return -1
+		}
+		return r
+	}, s)
+}
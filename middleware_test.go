@@ -0,0 +1,226 @@
+package hermes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var middlewareKey = []byte("a very secret key of at least 32 bytes")
+
+var middlewareAlgorithms = WithParseOptions(WithAllowedAlgorithms("HS256"))
+
+func middlewareToken(t *testing.T, claims map[string]interface{}) string {
+	compact, _, err := NewBuilder().Claims(NewJWTClaimsSet(claims)).Sign("HS256", middlewareKey)
+	assert.NoError(t, err)
+	return compact
+}
+
+func serveMiddleware(m *Middleware, r *http.Request) (*httptest.ResponseRecorder, *JWT) {
+	var seen *JWT
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if jwt, ok := JWTFromContext(r.Context()); ok {
+			seen = &jwt
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	rec := httptest.NewRecorder()
+	m.Handler(next).ServeHTTP(rec, r)
+	return rec, seen
+}
+
+func TestMiddlewareAuthorizationHeader(t *testing.T) {
+	m := NewMiddleware(StaticKeyResolver{"": middlewareKey}, middlewareAlgorithms, WithRealm("example"))
+	token := middlewareToken(t, map[string]interface{}{"sub": "joe"})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	rec, jwt := serveMiddleware(m, r)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	if assert.NotNil(t, jwt) {
+		assert.Equal(t, SignatureVerified, jwt.State())
+		sub, _ := jwt.Claims().GetClaimValue("sub")
+		assert.Equal(t, "joe", sub)
+	}
+
+	// No token
+	rec, jwt = serveMiddleware(m, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, `Bearer realm="example"`, rec.Header().Get("WWW-Authenticate"))
+	assert.Nil(t, jwt)
+
+	// Tampered token
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token+"x")
+	rec, jwt = serveMiddleware(m, r)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+	assert.Nil(t, jwt)
+
+	// Malformed header
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer")
+	rec, _ = serveMiddleware(m, r)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `error="invalid_request"`)
+}
+
+func TestMiddlewareValidation(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := NewMiddleware(StaticKeyResolver{"": middlewareKey}, middlewareAlgorithms, WithValidator(NewValidator(WithClock(func() time.Time { return now }), WithAudience("api"))))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+middlewareToken(t, map[string]interface{}{"aud": "api", "exp": now.Unix() - 10}))
+	rec, jwt := serveMiddleware(m, r)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, `Bearer error="invalid_token", error_description="token expired"`, rec.Header().Get("WWW-Authenticate"))
+	assert.Nil(t, jwt)
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+middlewareToken(t, map[string]interface{}{"aud": "api", "exp": now.Unix() + 10}))
+	rec, _ = serveMiddleware(m, r)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestMiddlewareScopes(t *testing.T) {
+	m := NewMiddleware(StaticKeyResolver{"": middlewareKey}, middlewareAlgorithms, WithRequiredScopes("read", "write"))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+middlewareToken(t, map[string]interface{}{"scope": "read"}))
+	rec, jwt := serveMiddleware(m, r)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, `Bearer error="insufficient_scope", error_description="insufficient scope", scope="read write"`, rec.Header().Get("WWW-Authenticate"))
+	assert.Nil(t, jwt)
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+middlewareToken(t, map[string]interface{}{"scope": "write read admin"}))
+	rec, _ = serveMiddleware(m, r)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestMiddlewareExtractors(t *testing.T) {
+	token := middlewareToken(t, map[string]interface{}{"sub": "joe"})
+	m := NewMiddleware(StaticKeyResolver{"": middlewareKey}, middlewareAlgorithms, WithTokenExtractors(FromAuthorizationHeader, FromCookie("session"), FromQuery("access_token")))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: token})
+	rec, jwt := serveMiddleware(m, r)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.NotNil(t, jwt)
+
+	rec, jwt = serveMiddleware(m, httptest.NewRequest(http.MethodGet, "/?access_token="+token, nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.NotNil(t, jwt)
+
+	// Only one transmission method may be used
+	r = httptest.NewRequest(http.MethodGet, "/?access_token="+token, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	rec, _ = serveMiddleware(m, r)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `error="invalid_request"`)
+
+	// The query is not consulted unless configured
+	rec, _ = serveMiddleware(NewMiddleware(StaticKeyResolver{"": middlewareKey}, middlewareAlgorithms), httptest.NewRequest(http.MethodGet, "/?access_token="+token, nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestMiddlewareRequiresAllowedAlgorithms(t *testing.T) {
+	token := middlewareToken(t, map[string]interface{}{"sub": "joe"})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	rec, jwt := serveMiddleware(NewMiddleware(StaticKeyResolver{"": middlewareKey}), r)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Nil(t, jwt)
+
+	rec, _ = serveMiddleware(NewMiddleware(StaticKeyResolver{"": middlewareKey}, WithParseOptions(WithAllowedAlgorithms("RS256"))), r)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec, _ = serveMiddleware(NewMiddleware(StaticKeyResolver{"": middlewareKey}, middlewareAlgorithms), r)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestMiddlewareErrorDescriptions(t *testing.T) {
+	var logged []error
+	resolver := JKUResolver{"https://idp.example.com/jwks": StaticKeyResolver{"1": middlewareKey}}
+	m := NewMiddleware(resolver, middlewareAlgorithms, WithErrorLog(func(r *http.Request, err error) {
+		logged = append(logged, err)
+	}))
+	serve := func(token string) string {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		rec, _ := serveMiddleware(m, r)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		return rec.Header().Get("WWW-Authenticate")
+	}
+
+	// Resolver errors naming key IDs and URLs stay out of the response
+	compact, _, _ := NewBuilder().HeaderParameter("jku", "https://idp.example.com/jwks").HeaderParameter("kid", "secret-kid").Claim("sub", "joe").Sign("HS256", middlewareKey)
+	challenge := serve(compact)
+	assert.Equal(t, `Bearer error="invalid_token", error_description="signature invalid"`, challenge)
+	if assert.Len(t, logged, 1) {
+		assert.ErrorContains(t, logged[0], "secret-kid")
+		var tokenErr *TokenError
+		assert.ErrorAs(t, logged[0], &tokenErr)
+	}
+
+	assert.Equal(t, `Bearer error="invalid_token", error_description="token malformed"`, serve("not.a.token"))
+	compact, _, _ = NewBuilder().HeaderParameter("jku", "https://idp.example.com/jwks").HeaderParameter("kid", "1").Claim("nbf", 4102444800).Sign("HS256", middlewareKey)
+	assert.Equal(t, `Bearer error="invalid_token", error_description="token not yet valid"`, serve(compact))
+	compact, _, _ = NewBuilder().HeaderParameter("jku", "https://idp.example.com/jwks").HeaderParameter("kid", "1").Claim("exp", "never").Sign("HS256", middlewareKey)
+	assert.Equal(t, `Bearer error="invalid_token", error_description="token claims invalid"`, serve(compact))
+	assert.Len(t, logged, 4)
+}
+
+func TestMiddlewarePolicyDescriptions(t *testing.T) {
+	var logged error
+	m := NewMiddleware(StaticKeyResolver{"": middlewareKey}, middlewareAlgorithms,
+		WithPolicy(Or(RequireAnyRole("super-admin"), ClaimEquals("department", "finance"))),
+		WithErrorLog(func(r *http.Request, err error) { logged = err }))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+middlewareToken(t, map[string]interface{}{"roles": []string{"editor"}}))
+	rec, _ := serveMiddleware(m, r)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, `Bearer error="insufficient_scope", error_description="insufficient privileges"`, rec.Header().Get("WWW-Authenticate"))
+	assert.ErrorIs(t, logged, ErrMissingRole)
+
+	// Required scopes are reported within a composed policy, but not its other branches
+	m = NewMiddleware(StaticKeyResolver{"": middlewareKey}, middlewareAlgorithms,
+		WithPolicy(Or(RequireAnyRole("super-admin"), RequireScopes("reports:read"))))
+	rec, _ = serveMiddleware(m, r)
+	assert.Equal(t, `Bearer error="insufficient_scope", error_description="insufficient scope", scope="reports:read"`, rec.Header().Get("WWW-Authenticate"))
+}
+
+func TestAuthParamValue(t *testing.T) {
+	assert.Equal(t, `key abc not found`, authParamValue("key \"abc\"\\ not found\n"))
+}
+
+func TestMiddlewarePolicy(t *testing.T) {
+	m := NewMiddleware(StaticKeyResolver{"": middlewareKey}, middlewareAlgorithms, WithPolicy(ClaimEquals("tenant", "acme")))
+	admin := m.Require(RequireAnyRole("admin"))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+middlewareToken(t, map[string]interface{}{"tenant": "other"}))
+	rec, _ := serveMiddleware(m, r)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, `Bearer error="insufficient_scope", error_description="insufficient privileges"`, rec.Header().Get("WWW-Authenticate"))
+
+	serve := func(token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		m.Handler(admin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))).ServeHTTP(rec, r)
+		return rec
+	}
+	assert.Equal(t, http.StatusNoContent, serve(middlewareToken(t, map[string]interface{}{"tenant": "acme", "roles": []string{"admin"}})).Code)
+	assert.Equal(t, http.StatusForbidden, serve(middlewareToken(t, map[string]interface{}{"tenant": "acme", "roles": []string{"editor"}})).Code)
+
+	// Require without the authentication middleware challenges the request
+	rec = httptest.NewRecorder()
+	admin(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+}
@@ -0,0 +1,82 @@
+// Reference: https://datatracker.ietf.org/doc/html/rfc7519#section-5.2
+package hermes
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NestedContentType is the "cty" value marking a JWE whose plaintext is itself a JWT.
+const NestedContentType = "JWT"
+
+// NestedJWT holds both layers of a nested JWT: the outer JWE and the inner JWS it carries.
+type NestedJWT struct {
+	Outer JWT
+	Inner JWT
+}
+
+func (n NestedJWT) EncryptionState() JWTState {
+	return n.Outer.State()
+}
+
+func (n NestedJWT) SignatureState() JWTState {
+	return n.Inner.State()
+}
+
+func (n NestedJWT) Claims() JWTClaimsSet {
+	return n.Inner.Claims()
+}
+
+// SignAndEncrypt signs the JWT with its header and wraps the resulting JWS in a JWE described by
+// encryptionHeader, setting "cty" to "JWT" as RFC 7519 §5.2 requires. It returns the JWE compact serialization.
+func (j JWT) SignAndEncrypt(signingKey interface{}, encryptionHeader JoseHeader, encryptionKey interface{}, opts ...JWEOption) (string, error) {
+	if !j.IsJWS() {
+		return "", fmt.Errorf("not a JWS")
+	}
+	jws, err := j.signed(signingKey)
+	if err != nil {
+		return "", err
+	}
+	h := make(JoseHeader, len(encryptionHeader)+1)
+	for k, v := range encryptionHeader {
+		h[k] = v
+	}
+	h[ContentTypeHeader] = NestedContentType
+	return encryptJWE(h, []byte(jws.compact), encryptionKey, newJWEOptions(opts))
+}
+
+// ParseNestedJWT decrypts a nested JWT and verifies the JWS it carries. The options apply to the inner JWS
+// as in ParseJWS, and WithDecryptOptions configures the decryption of the outer JWE. The returned NestedJWT
+// reports the state of both layers, also when an error is returned after the outer layer was parsed.
+func ParseNestedJWT(jwe string, decryptionKey interface{}, verificationKey interface{}, opts ...ParseOption) (NestedJWT, error) {
+	outer, err := ParseJWE(jwe)
+	if err != nil {
+		return NestedJWT{}, err
+	}
+	if !isNestedContentType(outer.header) {
+		outer.state = InvalidJWT
+		return NestedJWT{Outer: outer}, fmt.Errorf("JWE does not contain a nested JWT")
+	}
+	plaintext, err := outer.Decrypt(decryptionKey, newParseOptions(opts).jweOptions...)
+	if err != nil {
+		return NestedJWT{Outer: outer}, err
+	}
+	inner, err := ParseJWS(plaintext, opts...)
+	if err != nil {
+		return NestedJWT{Outer: outer}, err
+	}
+	if err := inner.Verify(verificationKey); err != nil {
+		return NestedJWT{Outer: outer, Inner: inner}, err
+	}
+	if inner.state != SignatureVerified {
+		return NestedJWT{Outer: outer, Inner: inner}, fmt.Errorf("invalid signature")
+	}
+	return NestedJWT{Outer: outer, Inner: inner}, nil
+}
+
+// isNestedContentType reports whether the "cty" header names a JWT, comparing case-insensitively and
+// allowing the "application/" prefix to be present as RFC 7515 §4.1.10 permits.
+func isNestedContentType(h JoseHeader) bool {
+	cty, _ := h.Parameter(ContentTypeHeader).(string)
+	return strings.EqualFold(cty, NestedContentType) || strings.EqualFold(cty, JWT_MEDIA_TYPE)
+}
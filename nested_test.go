@@ -0,0 +1,91 @@
+package hermes
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNestedJWT(t *testing.T) {
+	signingKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	encryptionKey := make([]byte, 16)
+	rand.Read(encryptionKey)
+	claims := NewJWTClaimsSet(map[string]interface{}{"iss": "joe", "sub": "1234567890"})
+	jwt := NewJWT(JoseHeader{"alg": "RS256"}, claims)
+	encryptionHeader := JoseHeader{"alg": AlgorithmA128KW, "enc": EncryptionA128GCM}
+
+	compact, err := jwt.SignAndEncrypt(signingKey, encryptionHeader, encryptionKey)
+	assert.NoError(t, err)
+	outer, err := ParseJWE(compact)
+	assert.NoError(t, err)
+	assert.Equal(t, NestedContentType, outer.Header().Parameter(ContentTypeHeader))
+	assert.Nil(t, encryptionHeader.Parameter(ContentTypeHeader))
+
+	nested, err := ParseNestedJWT(compact, encryptionKey, &signingKey.PublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, EncryptionVerified, nested.EncryptionState())
+	assert.Equal(t, SignatureVerified, nested.SignatureState())
+	iss, err := nested.Claims().GetClaimValue("iss")
+	assert.NoError(t, err)
+	assert.Equal(t, "joe", iss)
+
+	// A wrong verification key leaves the outer layer verified and the inner invalid
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	nested, err = ParseNestedJWT(compact, encryptionKey, &otherKey.PublicKey)
+	assert.Error(t, err)
+	assert.Equal(t, EncryptionVerified, nested.EncryptionState())
+	assert.Equal(t, SignatureInvalid, nested.SignatureState())
+
+	// A wrong decryption key fails the outer layer
+	nested, err = ParseNestedJWT(compact, make([]byte, 16), &signingKey.PublicKey)
+	assert.Error(t, err)
+	assert.Equal(t, EncryptionInvalid, nested.EncryptionState())
+}
+
+func TestNestedJWTContentType(t *testing.T) {
+	key := make([]byte, 16)
+	claims := NewJWTClaimsSet(map[string]interface{}{"sub": "1234567890"})
+	for _, cty := range []string{"JWT", "jwt", "application/jwt"} {
+		assert.True(t, isNestedContentType(JoseHeader{"cty": cty}), cty)
+	}
+	assert.False(t, isNestedContentType(JoseHeader{}))
+
+	// A JWE without cty "JWT" is not a nested JWT
+	compact, err := NewJWT(JoseHeader{"alg": AlgorithmA128KW, "enc": EncryptionA128GCM}, claims).Encrypt(key)
+	assert.NoError(t, err)
+	nested, err := ParseNestedJWT(compact, key, key)
+	assert.Error(t, err)
+	assert.Equal(t, InvalidJWT, nested.EncryptionState())
+
+	// Only JWS can be nested
+	_, err = NewJWT(JoseHeader{"alg": AlgorithmA128KW}, claims).SignAndEncrypt(key, JoseHeader{"alg": AlgorithmA128KW, "enc": EncryptionA128GCM}, key)
+	assert.Error(t, err)
+}
+
+func TestNestedJWTParseOptions(t *testing.T) {
+	signingKey := []byte("a very secret key of at least 32 bytes")
+	encryptionKey := make([]byte, 16)
+	rand.Read(encryptionKey)
+	claims := NewJWTClaimsSet(map[string]interface{}{"sub": "1234567890"})
+	compact, err := NewJWT(JoseHeader{"alg": "HS256"}, claims).SignAndEncrypt(signingKey, JoseHeader{"alg": AlgorithmA128KW, "enc": EncryptionA128GCM}, encryptionKey)
+	assert.NoError(t, err)
+
+	// The inner alg must be on the allow-list
+	nested, err := ParseNestedJWT(compact, encryptionKey, signingKey, WithAllowedAlgorithms("RS256", "ES256"))
+	assert.EqualError(t, err, "algorithm HS256 is not allowed")
+	assert.Equal(t, EncryptionVerified, nested.EncryptionState())
+
+	nested, err = ParseNestedJWT(compact, encryptionKey, signingKey, WithAllowedAlgorithms("HS256"))
+	assert.NoError(t, err)
+	assert.Equal(t, SignatureVerified, nested.SignatureState())
+
+	// Decrypt options reach the outer JWE
+	compact, err = NewJWT(JoseHeader{"alg": "HS256"}, claims).SignAndEncrypt(signingKey, JoseHeader{"alg": AlgorithmA128KW, "enc": EncryptionA128GCM, "zip": CompressionDeflate}, encryptionKey)
+	assert.NoError(t, err)
+	_, err = ParseNestedJWT(compact, encryptionKey, signingKey, WithDecryptOptions(WithMaxDecompressedSize(8)))
+	assert.Error(t, err)
+	_, err = ParseNestedJWT(compact, encryptionKey, signingKey)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,172 @@
+package hermes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	ScopeClaim  = "scope"
+	RolesClaim  = "roles"
+	GroupsClaim = "groups"
+)
+
+var (
+	ErrMissingRole   = errors.New("none of the required roles is granted")
+	ErrClaimMismatch = errors.New("claim does not have the required value")
+)
+
+// InsufficientScopeError reports the scopes a token lacks to satisfy RequireScopes.
+type InsufficientScopeError struct {
+	Required []string
+	Missing  []string
+}
+
+func (e *InsufficientScopeError) Error() string {
+	return "missing scope " + strings.Join(e.Missing, " ")
+}
+
+// Policy decides whether the claims of a verified JWT authorize a request, returning nil when they do.
+type Policy func(claims JWTClaimsSet) error
+
+// Authorize applies the policy to a JWT whose signature or encryption has been verified.
+func (p Policy) Authorize(jwt JWT) error {
+	if jwt.State() != SignatureVerified && jwt.State() != EncryptionVerified {
+		return fmt.Errorf("JWT is not verified")
+	}
+	return p(jwt.Claims())
+}
+
+// RequireScopes requires the space-delimited "scope" claim of RFC 8693 §4.2 and RFC 9068 §2.2.3 to hold every scope.
+func RequireScopes(scopes ...string) Policy {
+	return func(claims JWTClaimsSet) error {
+		granted := claimValues(claims, ScopeClaim)
+		var missing []string
+		for _, scope := range scopes {
+			if !containsString(granted, scope) {
+				missing = append(missing, scope)
+			}
+		}
+		if len(missing) > 0 {
+			return &InsufficientScopeError{Required: scopes, Missing: missing}
+		}
+		return nil
+	}
+}
+
+// RequireAnyRole requires one of the roles in the "roles" or "groups" claim of RFC 9068 §7.2, given as
+// an array or a space-delimited string.
+func RequireAnyRole(roles ...string) Policy {
+	return func(claims JWTClaimsSet) error {
+		granted := append(claimValues(claims, RolesClaim), claimValues(claims, GroupsClaim)...)
+		for _, role := range roles {
+			if containsString(granted, role) {
+				return nil
+			}
+		}
+		return &ClaimValidationError{Claim: RolesClaim, Err: ErrMissingRole}
+	}
+}
+
+// ClaimEquals requires the named claim to have the JSON value of value, so that numbers compare equal
+// whether they were decoded as json.Number or given as Go integers.
+func ClaimEquals(name string, value interface{}) Policy {
+	return func(claims JWTClaimsSet) error {
+		claim, err := claims.GetClaimValue(name)
+		if err != nil {
+			return &ClaimValidationError{Claim: name, Err: ErrMissingClaim}
+		}
+		if !jsonEqual(claim, value) {
+			return &ClaimValidationError{Claim: name, Err: ErrClaimMismatch}
+		}
+		return nil
+	}
+}
+
+// ClaimContains requires the named claim to be an array holding value, or a space-delimited string
+// holding it as one of its values.
+func ClaimContains(name string, value interface{}) Policy {
+	return func(claims JWTClaimsSet) error {
+		claim, err := claims.GetClaimValue(name)
+		if err != nil {
+			return &ClaimValidationError{Claim: name, Err: ErrMissingClaim}
+		}
+		switch c := claim.(type) {
+		case string:
+			if s, ok := value.(string); ok && containsString(strings.Fields(c), s) {
+				return nil
+			}
+		case []interface{}:
+			for _, element := range c {
+				if jsonEqual(element, value) {
+					return nil
+				}
+			}
+		case []string:
+			if s, ok := value.(string); ok && containsString(c, s) {
+				return nil
+			}
+		default:
+			return &ClaimValidationError{Claim: name, Err: ErrInvalidClaimType}
+		}
+		return &ClaimValidationError{Claim: name, Err: ErrClaimMismatch}
+	}
+}
+
+// And requires every policy to be satisfied, returning the first failure.
+func And(policies ...Policy) Policy {
+	return func(claims JWTClaimsSet) error {
+		for _, p := range policies {
+			if err := p(claims); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Or requires at least one policy to be satisfied, returning every failure otherwise.
+func Or(policies ...Policy) Policy {
+	return func(claims JWTClaimsSet) error {
+		errs := make([]error, 0, len(policies))
+		for _, p := range policies {
+			err := p(claims)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		if len(errs) == 0 {
+			return fmt.Errorf("no policy to satisfy")
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// claimValues reads a claim holding either a space-delimited string or an array of strings.
+func claimValues(claims JWTClaimsSet, name string) []string {
+	value, err := claims.GetClaimValue(name)
+	if err != nil {
+		return nil
+	}
+	if s, ok := value.(string); ok {
+		return strings.Fields(s)
+	}
+	values, _ := audienceValues(value)
+	return values
+}
+
+func jsonEqual(a, b interface{}) bool {
+	x, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	y, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(x, y)
+}
@@ -0,0 +1,78 @@
+package hermes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireScopes(t *testing.T) {
+	claims := NewJWTClaimsSet(map[string]interface{}{"scope": "openid profile email"})
+	assert.NoError(t, RequireScopes("openid", "email")(claims))
+	assert.NoError(t, RequireScopes()(claims))
+
+	err := RequireScopes("openid", "admin", "write")(claims)
+	var scopeErr *InsufficientScopeError
+	if assert.True(t, errors.As(err, &scopeErr)) {
+		assert.Equal(t, []string{"openid", "admin", "write"}, scopeErr.Required)
+		assert.Equal(t, []string{"admin", "write"}, scopeErr.Missing)
+	}
+	assert.EqualError(t, err, "missing scope admin write")
+	assert.Error(t, RequireScopes("openid")(NewJWTClaimsSet(map[string]interface{}{})))
+	assert.NoError(t, RequireScopes("read")(NewJWTClaimsSet(map[string]interface{}{"scope": []interface{}{"read"}})))
+}
+
+func TestRequireAnyRole(t *testing.T) {
+	claims := NewJWTClaimsSet(map[string]interface{}{"roles": []interface{}{"editor"}, "groups": []interface{}{"staff"}})
+	assert.NoError(t, RequireAnyRole("admin", "editor")(claims))
+	assert.NoError(t, RequireAnyRole("staff")(claims))
+	err := RequireAnyRole("admin")(claims)
+	assert.ErrorIs(t, err, ErrMissingRole)
+	assert.NoError(t, RequireAnyRole("admin")(NewJWTClaimsSet(map[string]interface{}{"roles": "admin editor"})))
+}
+
+func TestClaimPredicates(t *testing.T) {
+	claims, err := decodeClaims([]byte(`{"tenant":"acme","level":3,"email_verified":true,"amr":["pwd","otp"],"acr":"mfa phr"}`), false)
+	assert.NoError(t, err)
+	assert.NoError(t, ClaimEquals("tenant", "acme")(claims))
+	assert.NoError(t, ClaimEquals("level", 3)(claims))
+	assert.NoError(t, ClaimEquals("email_verified", true)(claims))
+	assert.ErrorIs(t, ClaimEquals("tenant", "other")(claims), ErrClaimMismatch)
+	assert.ErrorIs(t, ClaimEquals("missing", "x")(claims), ErrMissingClaim)
+
+	assert.NoError(t, ClaimContains("amr", "otp")(claims))
+	assert.NoError(t, ClaimContains("acr", "phr")(claims))
+	assert.ErrorIs(t, ClaimContains("amr", "hwk")(claims), ErrClaimMismatch)
+	assert.ErrorIs(t, ClaimContains("level", 3)(claims), ErrInvalidClaimType)
+
+	var claimErr *ClaimValidationError
+	assert.True(t, errors.As(ClaimContains("amr", "hwk")(claims), &claimErr))
+	assert.Equal(t, "amr", claimErr.Claim)
+}
+
+func TestPolicyComposition(t *testing.T) {
+	claims := NewJWTClaimsSet(map[string]interface{}{"scope": "read", "roles": []interface{}{"editor"}})
+	assert.NoError(t, And(RequireScopes("read"), RequireAnyRole("editor"))(claims))
+	assert.Error(t, And(RequireScopes("read"), RequireAnyRole("admin"))(claims))
+	assert.NoError(t, Or(RequireAnyRole("admin"), RequireScopes("read"))(claims))
+	assert.NoError(t, And()(claims))
+	assert.Error(t, Or()(claims))
+
+	err := Or(RequireAnyRole("admin"), RequireScopes("write"))(claims)
+	assert.ErrorIs(t, err, ErrMissingRole)
+	var scopeErr *InsufficientScopeError
+	assert.True(t, errors.As(err, &scopeErr))
+}
+
+func TestPolicyAuthorize(t *testing.T) {
+	key := []byte("a very secret key of at least 32 bytes")
+	compact, jwt, err := NewBuilder().Claim("scope", "read").Sign("HS256", key)
+	assert.NoError(t, err)
+	assert.NoError(t, RequireScopes("read").Authorize(jwt))
+
+	parsed, _ := ParseJWS(compact)
+	assert.Error(t, RequireScopes("read").Authorize(parsed))
+	assert.NoError(t, parsed.Verify(key))
+	assert.NoError(t, RequireScopes("read").Authorize(parsed))
+}
@@ -0,0 +1,189 @@
+package hermes
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+
+	cryptography "github.com/prulloac/hermes-jwt/cryptography"
+)
+
+const (
+	KeyIDHeader                 = "kid"
+	JWKSetURLHeader             = "jku"
+	X509CertificateSHA1Header   = "x5t"
+	X509CertificateSHA256Header = "x5t#S256"
+)
+
+// KeyResolver selects the candidate verification keys for a JWT from its header and its claims,
+// which have not been verified yet and must only be used to locate keys. Resolvers that fetch keys
+// over the network must stop when ctx is done.
+type KeyResolver interface {
+	ResolveKeys(ctx context.Context, header JoseHeader, claims JWTClaimsSet) ([]interface{}, error)
+}
+
+// KeyResolverFunc adapts a function to the KeyResolver interface.
+type KeyResolverFunc func(ctx context.Context, header JoseHeader, claims JWTClaimsSet) ([]interface{}, error)
+
+func (f KeyResolverFunc) ResolveKeys(ctx context.Context, header JoseHeader, claims JWTClaimsSet) ([]interface{}, error) {
+	return f(ctx, header, claims)
+}
+
+// StaticKeyResolver maps "kid" values to keys. Tokens without a "kid" are tried against every key.
+type StaticKeyResolver map[string]interface{}
+
+func (r StaticKeyResolver) ResolveKeys(_ context.Context, header JoseHeader, _ JWTClaimsSet) ([]interface{}, error) {
+	if kid, ok := header.Parameter(KeyIDHeader).(string); ok {
+		key, found := r[kid]
+		if !found {
+			return nil, fmt.Errorf("key %s not found", kid)
+		}
+		return []interface{}{key}, nil
+	}
+	kids := make([]string, 0, len(r))
+	for kid := range r {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	keys := make([]interface{}, len(kids))
+	for i, kid := range kids {
+		keys[i] = r[kid]
+	}
+	return keys, nil
+}
+
+// ResolveKeys returns the signature keys of the set matching the "kid", "x5t" and "x5t#S256" headers
+// that are present. Keys bound to a different "alg" are skipped.
+func (s JWKSet) ResolveKeys(_ context.Context, header JoseHeader, _ JWTClaimsSet) ([]interface{}, error) {
+	kid, hasKid := header.Parameter(KeyIDHeader).(string)
+	x5t, err := header.optionalBytesParameter(X509CertificateSHA1Header)
+	if err != nil {
+		return nil, err
+	}
+	x5tS256, err := header.optionalBytesParameter(X509CertificateSHA256Header)
+	if err != nil {
+		return nil, err
+	}
+	alg := header.Algorithm()
+	var keys []interface{}
+	for _, k := range s.Keys {
+		if hasKid && k.KeyID != kid {
+			continue
+		}
+		if k.Use != "" && k.Use != KeyUseSignature {
+			continue
+		}
+		if k.Algorithm != "" && k.Algorithm != alg {
+			continue
+		}
+		if x5t != nil && !bytes.Equal(x5t, k.certificateSHA1Thumbprint()) {
+			continue
+		}
+		if x5tS256 != nil && !bytes.Equal(x5tS256, k.certificateSHA256Thumbprint()) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no matching key found in JWK Set")
+	}
+	return keys, nil
+}
+
+// certificateSHA1Thumbprint returns the "x5t" of the key, computing it from the certificate chain when absent.
+func (k JWK) certificateSHA1Thumbprint() []byte {
+	if len(k.X509CertificateSHA1Thumbprint) > 0 {
+		return k.X509CertificateSHA1Thumbprint
+	}
+	if len(k.X509CertificateChain) > 0 {
+		sum := sha1.Sum(k.X509CertificateChain[0].Raw)
+		return sum[:]
+	}
+	return nil
+}
+
+// certificateSHA256Thumbprint returns the "x5t#S256" of the key, computing it from the certificate chain when absent.
+func (k JWK) certificateSHA256Thumbprint() []byte {
+	if len(k.X509CertificateSHA256Thumbprint) > 0 {
+		return k.X509CertificateSHA256Thumbprint
+	}
+	if len(k.X509CertificateChain) > 0 {
+		sum := sha256.Sum256(k.X509CertificateChain[0].Raw)
+		return sum[:]
+	}
+	return nil
+}
+
+// JKUResolver maps trusted "jku" URLs to the resolvers for their keys. Tokens naming any other
+// "jku" are rejected, so keys are never taken from a location chosen by the token.
+type JKUResolver map[string]KeyResolver
+
+func (r JKUResolver) ResolveKeys(ctx context.Context, header JoseHeader, claims JWTClaimsSet) ([]interface{}, error) {
+	jku, ok := header.Parameter(JWKSetURLHeader).(string)
+	if !ok {
+		return nil, fmt.Errorf("missing %s header parameter", JWKSetURLHeader)
+	}
+	resolver, found := r[jku]
+	if !found {
+		return nil, fmt.Errorf("untrusted %s header parameter %s", JWKSetURLHeader, jku)
+	}
+	return resolver.ResolveKeys(ctx, header, claims)
+}
+
+// ChainKeyResolver collects the candidate keys of each resolver in order. It fails only when no
+// resolver returns a key, reporting every resolver error.
+type ChainKeyResolver []KeyResolver
+
+func (c ChainKeyResolver) ResolveKeys(ctx context.Context, header JoseHeader, claims JWTClaimsSet) ([]interface{}, error) {
+	var keys []interface{}
+	var errs []error
+	for _, r := range c {
+		found, err := r.ResolveKeys(ctx, header, claims)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		keys = append(keys, found...)
+	}
+	if len(keys) == 0 {
+		if len(errs) == 0 {
+			return nil, fmt.Errorf("no key found")
+		}
+		return nil, errors.Join(errs...)
+	}
+	return keys, nil
+}
+
+// VerifyWith verifies the signature against the candidate keys returned by the resolver, succeeding
+// with the first key that validates it. The context bounds any network access of the resolver.
+func (j *JWT) VerifyWith(ctx context.Context, resolver KeyResolver) error {
+	if j.header.Algorithm() == cryptography.AlgorithmNone {
+		return fmt.Errorf("unsecured JWT has no signature to verify")
+	}
+	keys, err := resolver.ResolveKeys(ctx, j.header, j.payload)
+	if err != nil {
+		j.state = SignatureInvalid
+		return err
+	}
+	var errs []error
+	for _, key := range keys {
+		candidate := *j
+		if err := candidate.Verify(key); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if candidate.state == SignatureVerified {
+			*j = candidate
+			return nil
+		}
+	}
+	j.state = SignatureInvalid
+	if len(errs) > 0 {
+		return fmt.Errorf("signature could not be verified with any candidate key: %w", errors.Join(errs...))
+	}
+	return fmt.Errorf("signature could not be verified with any candidate key")
+}
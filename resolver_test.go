@@ -0,0 +1,112 @@
+package hermes
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticKeyResolver(t *testing.T) {
+	key1 := []byte("first secret key of at least 32 bytes")
+	key2 := []byte("second secret key of at least 32 bytes")
+	resolver := StaticKeyResolver{"1": key1, "2": key2}
+
+	compact, _, err := NewBuilder().HeaderParameter("kid", "2").Claim("sub", "joe").Sign("HS256", key2)
+	assert.NoError(t, err)
+	jwt, _ := ParseJWS(compact)
+	assert.NoError(t, jwt.VerifyWith(context.Background(), resolver))
+	assert.Equal(t, SignatureVerified, jwt.State())
+
+	// Without a kid every key is a candidate
+	compact, _, _ = NewBuilder().Claim("sub", "joe").Sign("HS256", key2)
+	jwt, _ = ParseJWS(compact)
+	assert.NoError(t, jwt.VerifyWith(context.Background(), resolver))
+	assert.Equal(t, SignatureVerified, jwt.State())
+
+	compact, _, _ = NewBuilder().HeaderParameter("kid", "3").Claim("sub", "joe").Sign("HS256", key2)
+	jwt, _ = ParseJWS(compact)
+	assert.Error(t, jwt.VerifyWith(context.Background(), resolver))
+	assert.Equal(t, SignatureInvalid, jwt.State())
+
+	compact, _, _ = NewBuilder().HeaderParameter("kid", "1").Claim("sub", "joe").Sign("HS256", key2)
+	jwt, _ = ParseJWS(compact)
+	assert.Error(t, jwt.VerifyWith(context.Background(), resolver))
+	assert.Equal(t, SignatureInvalid, jwt.State())
+}
+
+func TestJWKSetResolver(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	sha1Sum := sha1.Sum([]byte("certificate"))
+	sha256Sum := sha256.Sum256([]byte("certificate"))
+	set := JWKSet{Keys: []JWK{
+		{Key: &rsaKey.PublicKey, KeyID: "rsa", Use: KeyUseEncryption},
+		{Key: &rsaKey.PublicKey, KeyID: "rsa", Algorithm: "RS256", Use: KeyUseSignature},
+		{Key: &ecKey.PublicKey, KeyID: "ec", X509CertificateSHA1Thumbprint: sha1Sum[:], X509CertificateSHA256Thumbprint: sha256Sum[:]},
+	}}
+
+	compact, _, _ := NewBuilder().HeaderParameter("kid", "rsa").Claim("sub", "joe").Sign("RS256", rsaKey)
+	jwt, _ := ParseJWS(compact)
+	keys, err := set.ResolveKeys(context.Background(), jwt.Header(), jwt.Claims())
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.NoError(t, jwt.VerifyWith(context.Background(), set))
+	assert.Equal(t, SignatureVerified, jwt.State())
+
+	// Keys bound to another algorithm are not candidates
+	compact, _, _ = NewBuilder().HeaderParameter("kid", "rsa").Claim("sub", "joe").Sign("PS256", rsaKey)
+	jwt, _ = ParseJWS(compact)
+	assert.Error(t, jwt.VerifyWith(context.Background(), set))
+
+	compact, _, _ = NewBuilder().HeaderParameter("x5t", encodeSegment(sha1Sum[:])).Claim("sub", "joe").Sign("ES256", ecKey)
+	jwt, _ = ParseJWS(compact)
+	assert.NoError(t, jwt.VerifyWith(context.Background(), set))
+	assert.Equal(t, SignatureVerified, jwt.State())
+
+	compact, _, _ = NewBuilder().HeaderParameter("x5t#S256", encodeSegment(sha1Sum[:])).Claim("sub", "joe").Sign("ES256", ecKey)
+	jwt, _ = ParseJWS(compact)
+	assert.Error(t, jwt.VerifyWith(context.Background(), set))
+
+	compact, _, _ = NewBuilder().HeaderParameter("x5t#S256", encodeSegment(sha256Sum[:])).Claim("sub", "joe").Sign("ES256", ecKey)
+	jwt, _ = ParseJWS(compact)
+	assert.NoError(t, jwt.VerifyWith(context.Background(), set))
+}
+
+func TestChainKeyResolver(t *testing.T) {
+	key := []byte("a very secret key of at least 32 bytes")
+	compact, _, _ := NewBuilder().HeaderParameter("kid", "b").HeaderParameter("jku", "https://example.com/jwks").Claim("sub", "joe").Sign("HS256", key)
+
+	chain := ChainKeyResolver{StaticKeyResolver{"a": key}, JKUResolver{"https://example.com/jwks": StaticKeyResolver{"b": key}}}
+	jwt, _ := ParseJWS(compact)
+	assert.NoError(t, jwt.VerifyWith(context.Background(), chain))
+	assert.Equal(t, SignatureVerified, jwt.State())
+
+	// An untrusted jku is never resolved
+	chain = ChainKeyResolver{StaticKeyResolver{"a": key}, JKUResolver{"https://example.org/jwks": StaticKeyResolver{"b": key}}}
+	jwt, _ = ParseJWS(compact)
+	err := jwt.VerifyWith(context.Background(), chain)
+	assert.ErrorContains(t, err, "key b not found")
+	assert.ErrorContains(t, err, "untrusted jku")
+
+	// The context reaches every resolver of the chain
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	calls := 0
+	jwt, _ = ParseJWS(compact)
+	assert.NoError(t, jwt.VerifyWith(ctx, ChainKeyResolver{KeyResolverFunc(func(ctx context.Context, header JoseHeader, claims JWTClaimsSet) ([]interface{}, error) {
+		calls++
+		assert.Equal(t, "request", ctx.Value(ctxKey{}))
+		sub, _ := claims.GetClaimValue("sub")
+		assert.Equal(t, "joe", sub)
+		return []interface{}{[]byte("wrong"), key}, nil
+	})}))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, SignatureVerified, jwt.State())
+}
@@ -0,0 +1,76 @@
+// Reference: https://datatracker.ietf.org/doc/html/rfc7638
+package hermes
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+)
+
+const JWK_THUMBPRINT_URN = "urn:ietf:params:oauth:jwk-thumbprint"
+
+// Thumbprint computes the RFC 7638 thumbprint of the key's required members using the given hash.
+func (k JWK) Thumbprint(hash crypto.Hash) ([]byte, error) {
+	if !hash.Available() {
+		return nil, fmt.Errorf("hash function is not available")
+	}
+	input, err := k.thumbprintInput()
+	if err != nil {
+		return nil, err
+	}
+	h := hash.New()
+	h.Write(input)
+	return h.Sum(nil), nil
+}
+
+// ThumbprintKeyID returns the base64url encoded thumbprint, suitable as a "kid" value.
+func (k JWK) ThumbprintKeyID(hash crypto.Hash) (string, error) {
+	thumbprint, err := k.Thumbprint(hash)
+	if err != nil {
+		return "", err
+	}
+	return encodeSegment(thumbprint), nil
+}
+
+// ThumbprintURI returns the RFC 9278 "urn:ietf:params:oauth:jwk-thumbprint" URI of the key.
+func (k JWK) ThumbprintURI(hash crypto.Hash) (string, error) {
+	var name string
+	switch hash {
+	case crypto.SHA256:
+		name = "sha-256"
+	case crypto.SHA384:
+		name = "sha-384"
+	case crypto.SHA512:
+		name = "sha-512"
+	default:
+		return "", fmt.Errorf("unsupported thumbprint hash function")
+	}
+	kid, err := k.ThumbprintKeyID(hash)
+	if err != nil {
+		return "", err
+	}
+	return JWK_THUMBPRINT_URN + ":" + name + ":" + kid, nil
+}
+
+// thumbprintInput builds the canonical JSON of the required members, ordered lexicographically without whitespace.
+func (k JWK) thumbprintInput() ([]byte, error) {
+	raw, err := JWK{Key: k.Key}.toJSON()
+	if err != nil {
+		return nil, err
+	}
+	// encoding/json orders map keys lexicographically and emits no whitespace
+	var members map[string]string
+	switch raw.KeyType {
+	case KeyTypeRSA:
+		members = map[string]string{"e": raw.E, "kty": raw.KeyType, "n": raw.N}
+	case KeyTypeEC:
+		members = map[string]string{"crv": raw.Crv, "kty": raw.KeyType, "x": raw.X, "y": raw.Y}
+	case KeyTypeOKP:
+		members = map[string]string{"crv": raw.Crv, "kty": raw.KeyType, "x": raw.X}
+	case KeyTypeOct:
+		members = map[string]string{"k": raw.K, "kty": raw.KeyType}
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", raw.KeyType)
+	}
+	return json.Marshal(members)
+}
@@ -0,0 +1,52 @@
+package hermes
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThumbprintRSA(t *testing.T) {
+	// RFC 7638 Section 3.1
+	jwk, err := ParseJWK([]byte(`{"kty":"RSA","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw","e":"AQAB","alg":"RS256","kid":"2011-04-29"}`))
+	assert.NoError(t, err)
+	kid, err := jwk.ThumbprintKeyID(crypto.SHA256)
+	assert.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", kid)
+
+	// RFC 9278 Section 3
+	uri, err := jwk.ThumbprintURI(crypto.SHA256)
+	assert.NoError(t, err)
+	assert.Equal(t, "urn:ietf:params:oauth:jwk-thumbprint:sha-256:NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", uri)
+
+	_, err = jwk.ThumbprintURI(crypto.SHA1)
+	assert.Error(t, err)
+}
+
+func TestThumbprintOKP(t *testing.T) {
+	// RFC 8037 Appendix A.3
+	private, err := ParseJWK([]byte(`{"kty":"OKP","crv":"Ed25519","d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+	assert.NoError(t, err)
+	public, err := private.Public()
+	assert.NoError(t, err)
+	for _, k := range []JWK{private, public} {
+		kid, err := k.ThumbprintKeyID(crypto.SHA256)
+		assert.NoError(t, err)
+		assert.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", kid)
+	}
+}
+
+func TestThumbprintInput(t *testing.T) {
+	ec, err := ParseJWK([]byte(`{"kty":"EC","crv":"P-256","x":"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4","y":"4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM","use":"enc","kid":"1"}`))
+	assert.NoError(t, err)
+	input, err := ec.thumbprintInput()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"crv":"P-256","kty":"EC","x":"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4","y":"4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM"}`, string(input))
+
+	oct, err := ParseJWK([]byte(`{"kty":"oct","alg":"A128KW","k":"GawgguFyGrWKav7AX4VKUg"}`))
+	assert.NoError(t, err)
+	input, err = oct.thumbprintInput()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"k":"GawgguFyGrWKav7AX4VKUg","kty":"oct"}`, string(input))
+}
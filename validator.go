@@ -0,0 +1,257 @@
+// Reference: https://datatracker.ietf.org/doc/html/rfc7519#section-4.1
+package hermes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	ErrMissingClaim        = errors.New("claim is missing")
+	ErrInvalidClaimType    = errors.New("claim has an invalid type")
+	ErrTokenExpired        = errors.New("token is expired")
+	ErrTokenNotYetValid    = errors.New("token is not valid yet")
+	ErrTokenIssuedInFuture = errors.New("token is issued in the future")
+	ErrTokenTooOld         = errors.New("token exceeds the maximum age")
+	ErrInvalidIssuer       = errors.New("token issuer is not accepted")
+	ErrInvalidAudience     = errors.New("token audience is not accepted")
+)
+
+// ClaimValidationError reports the claim that failed validation. Err is one of the Err* values of this package.
+type ClaimValidationError struct {
+	Claim string
+	Err   error
+}
+
+func (e *ClaimValidationError) Error() string {
+	return fmt.Sprintf("invalid %s claim: %v", e.Claim, e.Err)
+}
+
+func (e *ClaimValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Validator checks the registered claims of a JWT claims set. The zero value only checks
+// the "exp", "nbf" and "iat" claims that are present against the current time.
+type Validator struct {
+	clock    func() time.Time
+	leeway   time.Duration
+	issuers  []string
+	audience []string
+	required []string
+	maxAge   time.Duration
+}
+
+type ValidatorOption func(*Validator)
+
+func NewValidator(opts ...ValidatorOption) Validator {
+	v := Validator{clock: time.Now}
+	for _, opt := range opts {
+		opt(&v)
+	}
+	return v
+}
+
+// WithClock sets the function used to read the current time.
+func WithClock(clock func() time.Time) ValidatorOption {
+	return func(v *Validator) {
+		v.clock = clock
+	}
+}
+
+// WithLeeway sets the clock skew tolerated when comparing "exp", "nbf" and "iat" with the current time.
+func WithLeeway(leeway time.Duration) ValidatorOption {
+	return func(v *Validator) {
+		v.leeway = leeway
+	}
+}
+
+// WithIssuer requires the "iss" claim to equal one of the given issuers.
+func WithIssuer(issuers ...string) ValidatorOption {
+	return func(v *Validator) {
+		v.issuers = append(v.issuers, issuers...)
+	}
+}
+
+// WithAudience requires the "aud" claim to contain at least one of the given audiences.
+func WithAudience(audience ...string) ValidatorOption {
+	return func(v *Validator) {
+		v.audience = append(v.audience, audience...)
+	}
+}
+
+// WithRequiredClaims requires the named claims to be present.
+func WithRequiredClaims(names ...string) ValidatorOption {
+	return func(v *Validator) {
+		v.required = append(v.required, names...)
+	}
+}
+
+// WithMaxAge rejects tokens whose "iat" claim is older than maxAge, making "iat" required.
+func WithMaxAge(maxAge time.Duration) ValidatorOption {
+	return func(v *Validator) {
+		v.maxAge = maxAge
+	}
+}
+
+// Validate checks the claims set, returning a *ClaimValidationError for the first claim that fails.
+func (v Validator) Validate(claims JWTClaimsSet) error {
+	now := time.Now()
+	if v.clock != nil {
+		now = v.clock()
+	}
+	for _, name := range v.required {
+		if _, err := claims.GetClaim(name); err != nil {
+			return &ClaimValidationError{Claim: name, Err: ErrMissingClaim}
+		}
+	}
+	exp, ok, err := timeClaim(claims, ExpirationTimeClaim)
+	if err != nil {
+		return err
+	}
+	if ok && !now.Before(exp.Add(v.leeway)) {
+		return &ClaimValidationError{Claim: ExpirationTimeClaim, Err: ErrTokenExpired}
+	}
+	nbf, ok, err := timeClaim(claims, NotBeforeClaim)
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(v.leeway).Before(nbf) {
+		return &ClaimValidationError{Claim: NotBeforeClaim, Err: ErrTokenNotYetValid}
+	}
+	iat, ok, err := timeClaim(claims, IssuedAtClaim)
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(v.leeway).Before(iat) {
+		return &ClaimValidationError{Claim: IssuedAtClaim, Err: ErrTokenIssuedInFuture}
+	}
+	if v.maxAge > 0 {
+		if !ok {
+			return &ClaimValidationError{Claim: IssuedAtClaim, Err: ErrMissingClaim}
+		}
+		if now.Sub(iat) > v.maxAge+v.leeway {
+			return &ClaimValidationError{Claim: IssuedAtClaim, Err: ErrTokenTooOld}
+		}
+	}
+	if len(v.issuers) > 0 {
+		if err := v.validateIssuer(claims); err != nil {
+			return err
+		}
+	}
+	if len(v.audience) > 0 {
+		if err := v.validateAudience(claims); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v Validator) validateIssuer(claims JWTClaimsSet) error {
+	value, err := claims.GetClaimValue(IssuerClaim)
+	if err != nil {
+		return &ClaimValidationError{Claim: IssuerClaim, Err: ErrMissingClaim}
+	}
+	iss, ok := value.(string)
+	if !ok {
+		return &ClaimValidationError{Claim: IssuerClaim, Err: ErrInvalidClaimType}
+	}
+	for _, issuer := range v.issuers {
+		if iss == issuer {
+			return nil
+		}
+	}
+	return &ClaimValidationError{Claim: IssuerClaim, Err: ErrInvalidIssuer}
+}
+
+func (v Validator) validateAudience(claims JWTClaimsSet) error {
+	value, err := claims.GetClaimValue(AudienceClaim)
+	if err != nil {
+		return &ClaimValidationError{Claim: AudienceClaim, Err: ErrMissingClaim}
+	}
+	aud, ok := audienceValues(value)
+	if !ok {
+		return &ClaimValidationError{Claim: AudienceClaim, Err: ErrInvalidClaimType}
+	}
+	for _, a := range aud {
+		for _, expected := range v.audience {
+			if a == expected {
+				return nil
+			}
+		}
+	}
+	return &ClaimValidationError{Claim: AudienceClaim, Err: ErrInvalidAudience}
+}
+
+// audienceValues reads an "aud" claim, which RFC 7519 §4.1.3 allows to be a single string or an array of strings.
+func audienceValues(value interface{}) ([]string, bool) {
+	switch aud := value.(type) {
+	case string:
+		return []string{aud}, true
+	case []string:
+		return aud, true
+	case Audience:
+		out := make([]string, len(aud))
+		for i, a := range aud {
+			out[i] = string(a)
+		}
+		return out, true
+	case []interface{}:
+		out := make([]string, len(aud))
+		for i, a := range aud {
+			s, ok := a.(string)
+			if !ok {
+				return nil, false
+			}
+			out[i] = s
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+// timeClaim reads a NumericDate claim, reporting whether it is present.
+func timeClaim(claims JWTClaimsSet, name string) (time.Time, bool, error) {
+	value, err := claims.GetClaimValue(name)
+	if err != nil {
+		return time.Time{}, false, nil
+	}
+	t, ok := numericDateTime(value)
+	if !ok {
+		return time.Time{}, false, &ClaimValidationError{Claim: name, Err: ErrInvalidClaimType}
+	}
+	return t, true, nil
+}
+
+// numericDateTime converts a decoded NumericDate value, in seconds since the epoch, to a time.
+func numericDateTime(value interface{}) (time.Time, bool) {
+	var seconds float64
+	switch v := value.(type) {
+	case float64:
+		seconds = v
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		seconds = f
+	case int:
+		return time.Unix(int64(v), 0), true
+	case int64:
+		return time.Unix(v, 0), true
+	case NumericDate:
+		seconds = float64(v)
+	case time.Time:
+		return v, true
+	default:
+		return time.Time{}, false
+	}
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return time.Time{}, false
+	}
+	return NumericDate(seconds).Time(), true
+}
@@ -0,0 +1,81 @@
+package hermes
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorTimeClaims(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	v := NewValidator(WithClock(func() time.Time { return now }))
+	claims := NewJWTClaimsSet(map[string]interface{}{
+		"exp": float64(now.Unix() + 60),
+		"nbf": float64(now.Unix() - 60),
+		"iat": json.Number("1699999940"),
+	})
+	assert.NoError(t, v.Validate(claims))
+
+	tests := []struct {
+		claim string
+		value interface{}
+		err   error
+	}{
+		{"exp", float64(now.Unix()), ErrTokenExpired},
+		{"exp", float64(now.Unix()) - 0.5, ErrTokenExpired},
+		{"nbf", float64(now.Unix() + 1), ErrTokenNotYetValid},
+		{"iat", now.Unix() + 10, ErrTokenIssuedInFuture},
+		{"exp", "tomorrow", ErrInvalidClaimType},
+	}
+	for _, tt := range tests {
+		c := NewJWTClaimsSet(map[string]interface{}{tt.claim: tt.value})
+		err := v.Validate(c)
+		var claimErr *ClaimValidationError
+		if assert.True(t, errors.As(err, &claimErr), tt.claim) {
+			assert.Equal(t, tt.claim, claimErr.Claim)
+		}
+		assert.ErrorIs(t, err, tt.err)
+	}
+
+	// Leeway tolerates clock skew
+	expired := NewJWTClaimsSet(map[string]interface{}{"exp": float64(now.Unix() - 5), "nbf": float64(now.Unix() + 5)})
+	assert.Error(t, v.Validate(expired))
+	assert.NoError(t, NewValidator(WithClock(func() time.Time { return now }), WithLeeway(10*time.Second)).Validate(expired))
+}
+
+func TestValidatorMaxAge(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	v := NewValidator(WithClock(func() time.Time { return now }), WithMaxAge(time.Hour))
+	assert.NoError(t, v.Validate(NewJWTClaimsSet(map[string]interface{}{"iat": float64(now.Unix() - 60)})))
+	assert.ErrorIs(t, v.Validate(NewJWTClaimsSet(map[string]interface{}{"iat": float64(now.Unix() - 7200)})), ErrTokenTooOld)
+	assert.ErrorIs(t, v.Validate(NewJWTClaimsSet(map[string]interface{}{})), ErrMissingClaim)
+}
+
+func TestValidatorIssuerAudience(t *testing.T) {
+	v := NewValidator(WithIssuer("https://a.example.com", "https://b.example.com"), WithAudience("api"))
+	assert.NoError(t, v.Validate(NewJWTClaimsSet(map[string]interface{}{"iss": "https://b.example.com", "aud": "api"})))
+	assert.NoError(t, v.Validate(NewJWTClaimsSet(map[string]interface{}{"iss": "https://a.example.com", "aud": []interface{}{"web", "api"}})))
+
+	err := v.Validate(NewJWTClaimsSet(map[string]interface{}{"iss": "https://c.example.com", "aud": "api"}))
+	assert.ErrorIs(t, err, ErrInvalidIssuer)
+	err = v.Validate(NewJWTClaimsSet(map[string]interface{}{"iss": "https://a.example.com", "aud": []interface{}{"web"}}))
+	assert.ErrorIs(t, err, ErrInvalidAudience)
+	err = v.Validate(NewJWTClaimsSet(map[string]interface{}{"iss": "https://a.example.com", "aud": []interface{}{"api", 1}}))
+	assert.ErrorIs(t, err, ErrInvalidClaimType)
+	err = v.Validate(NewJWTClaimsSet(map[string]interface{}{"iss": "https://a.example.com"}))
+	assert.ErrorIs(t, err, ErrMissingClaim)
+	assert.EqualError(t, err, "invalid aud claim: claim is missing")
+}
+
+func TestValidatorRequiredClaims(t *testing.T) {
+	v := NewValidator(WithRequiredClaims("sub", "jti"))
+	assert.NoError(t, v.Validate(NewJWTClaimsSet(map[string]interface{}{"sub": "1234567890", "jti": "abc"})))
+	err := v.Validate(NewJWTClaimsSet(map[string]interface{}{"sub": "1234567890"}))
+	var claimErr *ClaimValidationError
+	assert.True(t, errors.As(err, &claimErr))
+	assert.Equal(t, "jti", claimErr.Claim)
+	assert.ErrorIs(t, err, ErrMissingClaim)
+}